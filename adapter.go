@@ -2,6 +2,7 @@ package ydb
 
 import (
 	"context"
+	"time"
 
 	"github.com/opentracing/opentracing-go"
 	"github.com/ydb-platform/ydb-go-sdk/v3"
@@ -12,8 +13,63 @@ import (
 var _ spans.Adapter = (*adapter)(nil)
 
 type adapter struct {
-	tracer   opentracing.Tracer
-	detailer trace.Detailer
+	tracer          opentracing.Tracer
+	detailer        trace.Detailer
+	topicSampleRate float64
+
+	contentionThreshold time.Duration
+
+	ddlAudit   bool
+	ddlService string
+	ddlSink    func(DDLRecord)
+
+	extractors   []Extractor
+	implicitRoot bool
+	driver       *driverTimeline
+
+	slog     *slogBridge
+	encoding encoding
+
+	propagation   Propagation
+	queryComments bool
+
+	diagnostics *diagnostics
+	leaks       *LeakDetector
+	lateEvents  func(LateEvent)
+
+	// now is a clock of span timestamps and durations (see WithClock)
+	now func() time.Time
+}
+
+func newAdapter(opts ...Option) *adapter {
+	cfg := &adapter{
+		detailer:        trace.DetailsAll,
+		topicSampleRate: 1,
+
+		contentionThreshold: 100 * time.Millisecond,
+
+		now: time.Now,
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	if cfg.tracer == nil {
+		cfg.tracer = opentracing.GlobalTracer()
+	}
+	if len(cfg.encoding.rules) > 0 {
+		cfg.tracer = &rulesTracer{Tracer: cfg.tracer, encoding: &cfg.encoding}
+	}
+	if cfg.slog != nil {
+		cfg.slog.tracer = cfg.tracer
+	}
+
+	return cfg
+}
+
+// NewAdapter makes spans.Adapter for spans.WithTraces. Unlike WithTraces, it does not
+// add retry, transaction and other traces of this package.
+func NewAdapter(opts ...Option) spans.Adapter {
+	return newAdapter(opts...)
 }
 
 func (cfg *adapter) Details() trace.Details {
@@ -22,40 +78,98 @@ func (cfg *adapter) Details() trace.Details {
 
 func (cfg *adapter) SpanFromContext(ctx context.Context) spans.Span {
 	s := opentracing.SpanFromContext(ctx)
-	
 	if s == nil {
 		return noopSpan{}
 	}
+	if wrapped, has := ctx.Value(spanKey{}).(*span); has && wrapped.span == s {
+		return wrapped
+	}
 
-	return &span{
-		span: s,
+	wrapped := &span{
+		span:    s,
+		cfg:     cfg,
+		sampled: sampled(cfg.tracer, s.Context()),
+		ctx:     ctx,
 	}
+	// subsystem of span which is not started by adapter is unknown
+	if cfg.slog != nil && cfg.slog.enabled("") {
+		wrapped.slog = cfg.slog
+	}
+
+	return wrapped
 }
 
 func (cfg *adapter) Start(ctx context.Context, operationName string, fields ...spans.KeyValue) (
 	context.Context, spans.Span,
 ) {
-	tags := opentracing.Tags(make(map[string]interface{}))
-	for _, kv := range fieldsToFields(fields) {
-		tags[kv.Key()] = kv.Value()
+	ctx = withPeerFields(ctx, fields)
+	parent := cfg.parent(ctx)
+	var s opentracing.Span
+	switch {
+	case !sampled(cfg.tracer, parent) && parent == nil:
+		// span of noop tracer is not recorded, so options are not allocated at all
+		s = cfg.tracer.StartSpan(operationName)
+	case !sampled(cfg.tracer, parent):
+		// child of unsampled span is not recorded, so fields are not converted to tags
+		// and start time is not needed
+		s = cfg.tracer.StartSpan(operationName, opentracing.ChildOf(parent))
+	default:
+		tags := getTags()
+		cfg.encoding.fieldsToTags(tags, fields)
+		deadlineTags(ctx, tags)
+		peerFromContext(ctx).tags(tags)
+		if parent == nil {
+			cfg.rootTags(tags)
+		}
+		s = cfg.tracer.StartSpan(operationName, opentracing.ChildOf(parent), tags, opentracing.StartTime(cfg.now()))
+		putTags(tags)
 	}
-	s, childCtx := opentracing.StartSpanFromContextWithTracer(ctx, cfg.tracer, operationName, tags)
-
-	return childCtx, &span{
-		span: s,
+	wrapped := &span{
+		span:    s,
+		cfg:     cfg,
+		name:    operationName,
+		sampled: sampled(cfg.tracer, s.Context()),
+		ctx:     ctx,
 	}
-}
-
-func WithTraces(opts ...Option) ydb.Option {
-	cfg := &adapter{
-		detailer: trace.DetailsAll,
+	if cfg.leaks != nil {
+		cfg.leaks.track(wrapped, operationName)
 	}
-	for _, opt := range opts {
-		opt(cfg)
+	if cfg.diagnostics != nil {
+		// runtime stats are compared with wall clock, so clock of adapter is not used
+		wrapped.start = time.Now()
 	}
-	if cfg.tracer == nil {
-		cfg.tracer = opentracing.GlobalTracer()
+	if cfg.slog != nil {
+		if sub := subsystem(operationName); cfg.slog.enabled(sub) {
+			wrapped.slog = cfg.slog
+			wrapped.subsystem = sub
+		}
 	}
 
-	return spans.WithTraces(cfg)
+	return context.WithValue(opentracing.ContextWithSpan(ctx, s), spanKey{}, wrapped), wrapped
+}
+
+func WithTraces(opts ...Option) ydb.Option {
+	cfg := newAdapter(opts...)
+	driver := &driverTimeline{cfg: cfg}
+	cfg.driver = driver
+	driverTrace, discoveryTrace := timeline(cfg, driver)
+
+	return ydb.MergeOptions(
+		// retry loop and transaction traces go before spans.WithTraces: they replace
+		// context before the operation span is started and finish before it is ended
+		ydb.WithTraceRetry(retryTrace(cfg)),
+		ydb.WithTraceTable(retryTable(cfg)),
+		ydb.WithTraceQuery(retryQuery(cfg)),
+		ydb.WithTraceTable(txTable(cfg)),
+		ydb.WithTraceQuery(txQuery(cfg)),
+		spans.WithTraces(cfg),
+		ydb.WithTraceTable(txTableFinish(cfg)),
+		ydb.WithTraceQuery(peerQuery(cfg)),
+		ydb.WithTraceDatabaseSQL(databaseSQL(cfg)),
+		ydb.WithTraceScheme(ddlScheme(cfg)),
+		ydb.WithTraceQuery(ddlQuery(cfg)),
+		ydb.WithTraceDriver(driverTrace),
+		ydb.WithTraceDriver(connections(cfg, driver)),
+		ydb.WithTraceDiscovery(discoveryTrace),
+	)
 }
@@ -0,0 +1,291 @@
+package ydb
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/mocktracer"
+	"github.com/uber/jaeger-client-go"
+	"github.com/ydb-platform/ydb-go-sdk/v3"
+	"github.com/ydb-platform/ydb-go-sdk/v3/spans"
+	"github.com/ydb-platform/ydb-go-sdk/v3/trace"
+
+	"github.com/ydb-platform/ydb-go-sdk-opentracing/internal/stub"
+)
+
+// batch is a number of spans started (or ended) with stopped timer in Log, Error and End benchmarks
+const batch = 1024
+
+type (
+	benchTracer struct {
+		name   string
+		tracer opentracing.Tracer
+		reset  func()
+		closer io.Closer
+	}
+	detailLevel struct {
+		name    string
+		details trace.Details
+	}
+)
+
+var (
+	errBench = errors.New("bench")
+
+	levels = []detailLevel{
+		{"query", trace.QueryEvents},
+		{"query+retry", trace.QueryEvents | trace.RetryEvents},
+		{"query+driver", trace.QueryEvents | trace.DriverEvents},
+		{"all", trace.DetailsAll},
+	}
+
+	// stub and spans recorded from ydb-go-sdk for each detail level are shared by benchmarks
+	benchOnce    sync.Once
+	benchStub    *stub.Stub
+	benchScripts map[string][]*recordedSpan
+	errSetup     error
+)
+
+func TestMain(m *testing.M) {
+	code := m.Run()
+	if benchStub != nil {
+		benchStub.Stop()
+	}
+	os.Exit(code)
+}
+
+func benchTracers() []*benchTracer {
+	mock := mocktracer.New()
+	reporter := jaeger.NewInMemoryReporter()
+	jaegerTracer, closer := jaeger.NewTracer("bench", jaeger.NewConstSampler(true), reporter)
+
+	return []*benchTracer{
+		{name: "noop", tracer: opentracing.NoopTracer{}, reset: func() {}},
+		{name: "mocktracer", tracer: mock, reset: mock.Reset},
+		{name: "jaeger", tracer: jaegerTracer, reset: reporter.Reset, closer: closer},
+	}
+}
+
+// workload is a simulated YDB operation
+func workload(ctx context.Context, db *ydb.Driver) error {
+	return db.Query().Exec(ctx, "SELECT 1")
+}
+
+// record runs workload against stub and returns spans which ydb-go-sdk makes for it with given details
+func record(ctx context.Context, s *stub.Stub, details trace.Details) ([]*recordedSpan, error) {
+	r := &recorder{details: details}
+	db, err := ydb.Open(ctx, s.Endpoint(),
+		ydb.WithAnonymousCredentials(),
+		spans.WithTraces(r),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = db.Close(ctx)
+	}()
+	for i := 0; i < 10; i++ {
+		if err = workload(ctx, db); err != nil {
+			return nil, err
+		}
+	}
+
+	return r.recorded(), nil
+}
+
+func benchSetup(b *testing.B) (*stub.Stub, map[string][]*recordedSpan) {
+	b.Helper()
+	benchOnce.Do(func() {
+		if benchStub, errSetup = stub.Start(); errSetup != nil {
+			return
+		}
+		benchScripts = make(map[string][]*recordedSpan, len(levels))
+		for _, level := range levels {
+			if benchScripts[level.name], errSetup = record(context.Background(), benchStub, level.details); errSetup != nil {
+				return
+			}
+		}
+	})
+	if errSetup != nil {
+		b.Fatal(errSetup)
+	}
+
+	return benchStub, benchScripts
+}
+
+// benchEach runs bench for each detail level and tracer with spans recorded for the level
+func benchEach(b *testing.B, bench func(b *testing.B, t *benchTracer, level detailLevel, script []*recordedSpan)) {
+	_, scripts := benchSetup(b)
+	for _, level := range levels {
+		script := scripts[level.name]
+		if len(script) == 0 {
+			continue
+		}
+		b.Run(level.name, func(b *testing.B) {
+			for _, t := range benchTracers() {
+				b.Run(t.name, func(b *testing.B) {
+					bench(b, t, level, script)
+				})
+				if t.closer != nil {
+					_ = t.closer.Close()
+				}
+			}
+		})
+	}
+}
+
+func logFields(script []*recordedSpan) [][]spans.KeyValue {
+	fields := [][]spans.KeyValue{nil}
+	for _, rs := range script {
+		fields = append(fields, rs.logs...)
+	}
+
+	return fields
+}
+
+func endAll(started []spans.Span) {
+	for _, s := range started {
+		s.End()
+	}
+}
+
+func BenchmarkStart(b *testing.B) {
+	benchEach(b, func(b *testing.B, t *benchTracer, _ detailLevel, script []*recordedSpan) {
+		adapter := NewAdapter(WithTracer(t.tracer))
+		ctx := context.Background()
+		started := make([]spans.Span, 0, batch)
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			rs := script[i%len(script)]
+			_, s := adapter.Start(ctx, rs.name, rs.fields...)
+			started = append(started, s)
+			if len(started) == batch {
+				b.StopTimer()
+				endAll(started)
+				started = started[:0]
+				t.reset()
+				b.StartTimer()
+			}
+		}
+		b.StopTimer()
+		endAll(started)
+		t.reset()
+	})
+}
+
+func BenchmarkEnd(b *testing.B) {
+	benchEach(b, func(b *testing.B, t *benchTracer, _ detailLevel, script []*recordedSpan) {
+		adapter := NewAdapter(WithTracer(t.tracer))
+		ctx := context.Background()
+		started := make([]spans.Span, 0, batch)
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if i%batch == 0 {
+				b.StopTimer()
+				t.reset()
+				started = started[:0]
+				for j := 0; j < batch; j++ {
+					rs := script[(i+j)%len(script)]
+					_, s := adapter.Start(ctx, rs.name, rs.fields...)
+					started = append(started, s)
+				}
+				b.StartTimer()
+			}
+			started[i%batch].End(script[i%len(script)].endFields...)
+		}
+		b.StopTimer()
+		for i := b.N % batch; i > 0 && i < batch; i++ {
+			started[i].End()
+		}
+		t.reset()
+	})
+}
+
+// benchEvent measures Log or Error on span from context
+func benchEvent(b *testing.B, event func(s spans.Span, fields []spans.KeyValue)) {
+	benchEach(b, func(b *testing.B, t *benchTracer, _ detailLevel, script []*recordedSpan) {
+		fields := logFields(script)
+		adapter := NewAdapter(WithTracer(t.tracer))
+		ctx, s := adapter.Start(context.Background(), "event")
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			event(adapter.SpanFromContext(ctx), fields[i%len(fields)])
+			if i%batch == batch-1 {
+				b.StopTimer()
+				s.End()
+				t.reset()
+				ctx, s = adapter.Start(context.Background(), "event")
+				b.StartTimer()
+			}
+		}
+		b.StopTimer()
+		s.End()
+		t.reset()
+	})
+}
+
+func BenchmarkLog(b *testing.B) {
+	benchEvent(b, func(s spans.Span, fields []spans.KeyValue) {
+		s.Log("event", fields...)
+	})
+}
+
+func BenchmarkError(b *testing.B) {
+	benchEvent(b, func(s spans.Span, fields []spans.KeyValue) {
+		s.Error(errBench, fields...)
+	})
+}
+
+// benchWorkload runs workload against stub through driver with WithTraces,
+// or through untraced driver if tracer is nil
+func benchWorkload(b *testing.B, s *stub.Stub, t *benchTracer, details trace.Details) {
+	ctx := context.Background()
+	opts := []ydb.Option{ydb.WithAnonymousCredentials()}
+	if t != nil {
+		opts = append(opts, WithTraces(
+			WithTracer(t.tracer),
+			WithDetailer(details),
+		))
+	}
+	db, err := ydb.Open(ctx, s.Endpoint(), opts...)
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer func() {
+		_ = db.Close(ctx)
+	}()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err = workload(ctx, db); err != nil {
+			b.Fatal(err)
+		}
+		if t != nil && i%batch == batch-1 {
+			b.StopTimer()
+			t.reset()
+			b.StartTimer()
+		}
+	}
+	b.StopTimer()
+	if t != nil {
+		t.reset()
+	}
+}
+
+func BenchmarkWorkload(b *testing.B) {
+	s, _ := benchSetup(b)
+	b.Run("untraced", func(b *testing.B) {
+		benchWorkload(b, s, nil, 0)
+	})
+	benchEach(b, func(b *testing.B, t *benchTracer, level detailLevel, _ []*recordedSpan) {
+		benchWorkload(b, s, t, level.details)
+	})
+}
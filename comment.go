@@ -0,0 +1,53 @@
+package ydb
+
+import (
+	"context"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/opentracing/opentracing-go"
+)
+
+// comment appends span context of current span from ctx to query as YQL comment
+// like /* traceparent='00-...-01' */, so YDB query logs and system views carry trace identifier.
+// Comment is appended on new line: leading pragmas like --!syntax_v1 must stay first.
+func (cfg *adapter) comment(ctx context.Context, query string) string {
+	sc := cfg.parent(ctx)
+	if sc == nil {
+		return query
+	}
+	tc, ok := nativeContext(cfg.tracer, sc)
+	if !ok {
+		return query
+	}
+	format := cfg.propagation
+	if format == PropagationTracer {
+		format = PropagationW3C
+	}
+	carrier := opentracing.TextMapCarrier{}
+	tc.write(format, carrier)
+	pairs := make([]string, 0, len(carrier))
+	for _, k := range sortedKeys(carrier) {
+		pairs = append(pairs, k+"='"+url.QueryEscape(carrier[k])+"'")
+	}
+
+	return query + "\n/* " + strings.Join(pairs, ",") + " */"
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// Comment appends span context of current span from ctx to YQL text as comment in format set by
+// WithPropagation (traceparent if format is native format of tracer). It is a helper for queries
+// of native clients; queries of database/sql are commented by WrapConnector with WithQueryComments.
+func (p *Propagator) Comment(ctx context.Context, yql string) string {
+	return p.cfg.comment(ctx, yql)
+}
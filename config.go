@@ -0,0 +1,238 @@
+package ydb
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/ydb-platform/ydb-go-sdk/v3/trace"
+	"gopkg.in/yaml.v3"
+)
+
+// Environment variables read by Config.Env and LoadConfig
+const (
+	EnvConfig          = "YDB_TRACING_CONFIG"
+	EnvDetails         = "YDB_TRACING_DETAILS"
+	EnvTopicSampleRate = "YDB_TRACING_TOPIC_SAMPLE_RATE"
+	EnvRedact          = "YDB_TRACING_REDACT"
+)
+
+// Actions of AttributeRule
+const (
+	AttributeDrop   = "drop"
+	AttributeRedact = "redact"
+	AttributeRename = "rename"
+
+	redacted = "<redacted>"
+)
+
+var errConfig = errors.New("ydb tracing config")
+
+type (
+	// Config is a declarative configuration of adapter which may be read from JSON or YAML
+	// file (see LoadConfig) and overridden by environment variables (see Config.Env)
+	Config struct {
+		// Details are names of ydb-go-sdk trace details, like ydb.query, ydb.driver.conn or all.
+		// All details are traced if there are no names.
+		Details []string `json:"details,omitempty" yaml:"details,omitempty"`
+		// TopicSampleRate is a share of topic messages traced by Topics (see WithTopicSampleRate).
+		// It doesn't sample other spans: sampling of traces is configured in sampler of tracer.
+		TopicSampleRate *float64 `json:"topic_sample_rate,omitempty" yaml:"topic_sample_rate,omitempty"`
+		// Redact are keys of span attributes whose values are replaced with <redacted>.
+		// Redaction wins over rule of Attributes with the same key.
+		Redact []string `json:"redact,omitempty" yaml:"redact,omitempty"`
+		// Attributes are rules of span attributes. Rules apply to tags and log fields of all spans
+		// started by adapter (fields of ydb-go-sdk spans and tags of this package, like ydb.tx.id)
+		// and to slog records. Redact or drop rule of db.statement covers query text everywhere:
+		// query and Query fields of ydb-go-sdk spans and DDLRecord.Statement.
+		Attributes []AttributeRule `json:"attributes,omitempty" yaml:"attributes,omitempty"`
+	}
+	// AttributeRule drops, redacts or renames span attribute with key
+	AttributeRule struct {
+		Key    string `json:"key" yaml:"key"`
+		Action string `json:"action" yaml:"action"`
+		// To is a new key for rename
+		To string `json:"to,omitempty" yaml:"to,omitempty"`
+	}
+)
+
+// LoadConfig reads config from JSON or YAML file (by extension) at path, or at YDB_TRACING_CONFIG
+// if path is empty, overrides it with environment variables and validates it.
+// Empty config is used if there is no file.
+func LoadConfig(path string) (*Config, error) {
+	if path == "" {
+		path = os.Getenv(EnvConfig)
+	}
+	c := &Config{}
+	if path != "" {
+		var err error
+		if c, err = ReadConfig(path); err != nil {
+			return nil, err
+		}
+	}
+	if err := c.Env(); err != nil {
+		return nil, err
+	}
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// ReadConfig reads config from JSON (.json) or YAML (.yaml, .yml) file. Unknown fields are errors.
+func ReadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errConfig, err)
+	}
+	c := &Config{}
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".json":
+		d := json.NewDecoder(bytes.NewReader(data))
+		d.DisallowUnknownFields()
+		err = d.Decode(c)
+	case ".yaml", ".yml":
+		d := yaml.NewDecoder(bytes.NewReader(data))
+		d.KnownFields(true)
+		err = d.Decode(c)
+	default:
+		return nil, fmt.Errorf("%w: %s: unknown file extension %q, want .json, .yaml or .yml", errConfig, path, ext)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %w", errConfig, path, err)
+	}
+
+	return c, nil
+}
+
+// Env overrides config with YDB_TRACING_DETAILS and YDB_TRACING_REDACT (comma separated lists)
+// and YDB_TRACING_TOPIC_SAMPLE_RATE
+func (c *Config) Env() error {
+	if v, has := os.LookupEnv(EnvDetails); has {
+		c.Details = splitList(v)
+	}
+	if v, has := os.LookupEnv(EnvTopicSampleRate); has {
+		rate, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		if err != nil {
+			return fmt.Errorf("%w: %s: %q is not a number", errConfig, EnvTopicSampleRate, v)
+		}
+		c.TopicSampleRate = &rate
+	}
+	if v, has := os.LookupEnv(EnvRedact); has {
+		c.Redact = splitList(v)
+	}
+
+	return nil
+}
+
+func splitList(v string) []string {
+	var list []string
+	for _, s := range strings.Split(v, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			list = append(list, s)
+		}
+	}
+
+	return list
+}
+
+// Validate reports all errors of config
+func (c *Config) Validate() error {
+	var errs []error
+	if _, err := c.details(); err != nil {
+		errs = append(errs, err)
+	}
+	if c.TopicSampleRate != nil && (*c.TopicSampleRate < 0 || *c.TopicSampleRate > 1) {
+		errs = append(errs,
+			fmt.Errorf("%w: topic_sample_rate: %v is out of range [0, 1]", errConfig, *c.TopicSampleRate),
+		)
+	}
+	for i, key := range c.Redact {
+		if key == "" {
+			errs = append(errs, fmt.Errorf("%w: redact[%d]: empty key", errConfig, i))
+		}
+	}
+	keys := make(map[string]int, len(c.Attributes))
+	for i, r := range c.Attributes {
+		if r.Key == "" {
+			errs = append(errs, fmt.Errorf("%w: attributes[%d]: empty key", errConfig, i))
+		}
+		if j, has := keys[r.Key]; has {
+			errs = append(errs, fmt.Errorf("%w: attributes[%d]: key %q is already used by attributes[%d]",
+				errConfig, i, r.Key, j))
+		}
+		keys[r.Key] = i
+		switch r.Action {
+		case AttributeDrop, AttributeRedact:
+		case AttributeRename:
+			if r.To == "" {
+				errs = append(errs, fmt.Errorf("%w: attributes[%d]: rename of %q needs \"to\" key", errConfig, i, r.Key))
+			}
+		default:
+			errs = append(errs, fmt.Errorf("%w: attributes[%d]: unknown action %q, want %s, %s or %s",
+				errConfig, i, r.Action, AttributeDrop, AttributeRedact, AttributeRename))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// details resolves names of details, all details are traced if there are no names
+func (c *Config) details() (trace.Details, error) {
+	if len(c.Details) == 0 {
+		return trace.DetailsAll, nil
+	}
+	var details trace.Details
+	for _, name := range c.Details {
+		if name == "all" {
+			details |= trace.DetailsAll
+
+			continue
+		}
+		if !strings.HasPrefix(name, "ydb.") {
+			name = "ydb." + name
+		}
+		d := trace.MatchDetails("^"+regexp.QuoteMeta(name)+"$", trace.WithDefaultDetails(0))
+		if d == 0 {
+			return 0, fmt.Errorf("%w: details: unknown name %q", errConfig, name)
+		}
+		details |= d
+	}
+
+	return details, nil
+}
+
+// Options validates config and converts it to options of adapter
+func (c *Config) Options() ([]Option, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	details, _ := c.details()
+	opts := []Option{WithDetailer(details)}
+	if c.TopicSampleRate != nil {
+		opts = append(opts, WithTopicSampleRate(*c.TopicSampleRate))
+	}
+	rules := make(map[string]AttributeRule, len(c.Redact)+len(c.Attributes))
+	for _, r := range c.Attributes {
+		rules[r.Key] = r
+	}
+	// redaction wins over attribute rules, so key redacted with environment is not leaked by file
+	for _, key := range c.Redact {
+		rules[key] = AttributeRule{Key: key, Action: AttributeRedact}
+	}
+	if len(rules) > 0 {
+		rules = withRules(rules)
+		opts = append(opts, func(c *adapter) {
+			c.encoding.rules = rules
+		})
+	}
+
+	return opts, nil
+}
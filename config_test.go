@@ -0,0 +1,376 @@
+package ydb
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/log"
+	"github.com/opentracing/opentracing-go/mocktracer"
+)
+
+func rate(v float64) *float64 {
+	return &v
+}
+
+func TestReadConfig(t *testing.T) {
+	want := &Config{
+		Details:         []string{"ydb.query", "table"},
+		TopicSampleRate: rate(0.5),
+		Redact:          []string{"db.statement"},
+		Attributes:      []AttributeRule{{Key: "ydb.node_id", Action: AttributeRename, To: "db.ydb.node_id"}},
+	}
+	for _, tt := range []struct {
+		name string
+		file string
+		data string
+		want *Config
+		err  string
+	}{
+		{
+			name: "json",
+			file: "tracing.json",
+			data: `{"details": ["ydb.query", "table"], "topic_sample_rate": 0.5, "redact": ["db.statement"],
+				"attributes": [{"key": "ydb.node_id", "action": "rename", "to": "db.ydb.node_id"}]}`,
+			want: want,
+		},
+		{
+			name: "yaml",
+			file: "tracing.yaml",
+			data: "details: [ydb.query, table]\ntopic_sample_rate: 0.5\nredact: [db.statement]\n" +
+				"attributes:\n  - key: ydb.node_id\n    action: rename\n    to: db.ydb.node_id\n",
+			want: want,
+		},
+		{
+			name: "yml",
+			file: "tracing.YML",
+			data: "redact: [db.statement]\n",
+			want: &Config{Redact: []string{"db.statement"}},
+		},
+		{
+			name: "json unknown field",
+			file: "tracing.json",
+			data: `{"sample_rate": 0.5}`,
+			err:  `unknown field "sample_rate"`,
+		},
+		{
+			name: "yaml unknown field",
+			file: "tracing.yaml",
+			data: "sample_rate: 0.5\n",
+			err:  "field sample_rate not found",
+		},
+		{
+			name: "unknown extension",
+			file: "tracing.toml",
+			data: "",
+			err:  `unknown file extension ".toml"`,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			if err := os.WriteFile(path, []byte(tt.data), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			c, err := ReadConfig(path)
+			if tt.err != "" {
+				if !errors.Is(err, errConfig) || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("ReadConfig() error = %v, want %q", err, tt.err)
+				}
+
+				return
+			}
+			if err != nil {
+				t.Fatalf("ReadConfig() error = %v", err)
+			}
+			if !reflect.DeepEqual(c, tt.want) {
+				t.Errorf("ReadConfig() = %+v, want %+v", c, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tracing.yaml")
+	if err := os.WriteFile(path, []byte("details: [ydb.query]\nredact: [db.statement]\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(EnvConfig, path)
+	t.Setenv(EnvDetails, "ydb.table, ydb.retry")
+	c, err := LoadConfig("")
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	want := &Config{Details: []string{"ydb.table", "ydb.retry"}, Redact: []string{"db.statement"}}
+	if !reflect.DeepEqual(c, want) {
+		t.Errorf("LoadConfig() = %+v, want %+v", c, want)
+	}
+
+	t.Setenv(EnvDetails, "ydb.unknown")
+	if _, err = LoadConfig(""); !errors.Is(err, errConfig) {
+		t.Errorf("LoadConfig() of invalid config error = %v, want %v", err, errConfig)
+	}
+	if _, err = LoadConfig(filepath.Join(t.TempDir(), "missing.yaml")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("LoadConfig() of missing file error = %v, want %v", err, os.ErrNotExist)
+	}
+}
+
+func TestConfigEnv(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		env  map[string]string
+		want Config
+		err  string
+	}{
+		{
+			name: "no variables",
+			want: Config{Details: []string{"ydb.query"}, Redact: []string{"db.statement"}},
+		},
+		{
+			name: "lists",
+			env:  map[string]string{EnvDetails: " ydb.table,,ydb.retry ", EnvRedact: "query"},
+			want: Config{Details: []string{"ydb.table", "ydb.retry"}, Redact: []string{"query"}},
+		},
+		{
+			name: "empty lists",
+			env:  map[string]string{EnvDetails: "", EnvRedact: ""},
+			want: Config{},
+		},
+		{
+			name: "topic sample rate",
+			env:  map[string]string{EnvTopicSampleRate: " 0.25 "},
+			want: Config{Details: []string{"ydb.query"}, TopicSampleRate: rate(0.25), Redact: []string{"db.statement"}},
+		},
+		{
+			name: "bad topic sample rate",
+			env:  map[string]string{EnvTopicSampleRate: "half"},
+			err:  `YDB_TRACING_TOPIC_SAMPLE_RATE: "half" is not a number`,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, key := range []string{EnvDetails, EnvTopicSampleRate, EnvRedact} {
+				if v, has := tt.env[key]; has {
+					t.Setenv(key, v)
+				} else {
+					// t.Setenv restores variable after test, so it is unset after registration of cleanup
+					t.Setenv(key, "")
+					os.Unsetenv(key)
+				}
+			}
+			c := Config{Details: []string{"ydb.query"}, Redact: []string{"db.statement"}}
+			err := c.Env()
+			if tt.err != "" {
+				if !errors.Is(err, errConfig) || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("Env() error = %v, want %q", err, tt.err)
+				}
+
+				return
+			}
+			if err != nil {
+				t.Fatalf("Env() error = %v", err)
+			}
+			if !reflect.DeepEqual(c, tt.want) {
+				t.Errorf("Env() = %+v, want %+v", c, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	for _, tt := range []struct {
+		name   string
+		config Config
+		err    string
+	}{
+		{
+			name: "valid",
+			config: Config{
+				Details:         []string{"all", "query", "ydb.table"},
+				TopicSampleRate: rate(1),
+				Redact:          []string{"db.statement"},
+				Attributes: []AttributeRule{
+					{Key: "a", Action: AttributeDrop},
+					{Key: "b", Action: AttributeRedact},
+					{Key: "c", Action: AttributeRename, To: "d"},
+				},
+			},
+		},
+		{
+			name:   "unknown detail",
+			config: Config{Details: []string{"ydb.unknown"}},
+			err:    `details: unknown name "ydb.unknown"`,
+		},
+		{
+			name:   "negative topic sample rate",
+			config: Config{TopicSampleRate: rate(-0.1)},
+			err:    "topic_sample_rate: -0.1 is out of range [0, 1]",
+		},
+		{
+			name:   "big topic sample rate",
+			config: Config{TopicSampleRate: rate(1.5)},
+			err:    "topic_sample_rate: 1.5 is out of range [0, 1]",
+		},
+		{
+			name:   "empty redact key",
+			config: Config{Redact: []string{"db.statement", ""}},
+			err:    "redact[1]: empty key",
+		},
+		{
+			name:   "empty attribute key",
+			config: Config{Attributes: []AttributeRule{{Action: AttributeDrop}}},
+			err:    "attributes[0]: empty key",
+		},
+		{
+			name: "duplicate attribute key",
+			config: Config{Attributes: []AttributeRule{
+				{Key: "a", Action: AttributeDrop},
+				{Key: "a", Action: AttributeRedact},
+			}},
+			err: `attributes[1]: key "a" is already used by attributes[0]`,
+		},
+		{
+			name:   "rename without to",
+			config: Config{Attributes: []AttributeRule{{Key: "a", Action: AttributeRename}}},
+			err:    `attributes[0]: rename of "a" needs "to" key`,
+		},
+		{
+			name:   "unknown action",
+			config: Config{Attributes: []AttributeRule{{Key: "a", Action: "hide"}}},
+			err:    `attributes[0]: unknown action "hide", want drop, redact or rename`,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate()
+			if tt.err == "" {
+				if err != nil {
+					t.Errorf("Validate() error = %v", err)
+				}
+
+				return
+			}
+			if !errors.Is(err, errConfig) || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("Validate() error = %v, want %q", err, tt.err)
+			}
+			if _, optsErr := tt.config.Options(); optsErr == nil {
+				t.Error("Options() of invalid config has no error")
+			}
+		})
+	}
+}
+
+func TestConfigValidateJoinsErrors(t *testing.T) {
+	c := Config{TopicSampleRate: rate(2), Redact: []string{""}}
+	err := c.Validate()
+	for _, want := range []string{"topic_sample_rate", "redact[0]"} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Validate() error = %v, want %q", err, want)
+		}
+	}
+}
+
+// rulesAdapter makes adapter with options of config which records spans to mock tracer
+func rulesAdapter(t *testing.T, c Config) (*adapter, *mocktracer.MockTracer) {
+	t.Helper()
+	opts, err := c.Options()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tracer := mocktracer.New()
+
+	return newAdapter(append(opts, WithTracer(tracer))...), tracer
+}
+
+func mockFields(s *mocktracer.MockSpan) map[string]string {
+	fields := map[string]string{}
+	for _, r := range s.Logs() {
+		for _, f := range r.Fields {
+			fields[f.Key] = f.ValueString
+		}
+	}
+
+	return fields
+}
+
+func TestConfigRedactWinsOverRename(t *testing.T) {
+	cfg, tracer := rulesAdapter(t, Config{
+		Redact:     []string{"secret"},
+		Attributes: []AttributeRule{{Key: "secret", Action: AttributeRename, To: "public"}},
+	})
+	s := cfg.tracer.StartSpan("span", opentracing.Tag{Key: "secret", Value: "start"})
+	s.SetTag("secret", "tag")
+	s.LogFields(log.String("secret", "field"))
+	s.Finish()
+
+	spans := tracer.FinishedSpans()
+	if len(spans) != 1 {
+		t.Fatalf("finished spans = %d, want 1", len(spans))
+	}
+	if tags := spans[0].Tags(); !reflect.DeepEqual(tags, map[string]interface{}{"secret": redacted}) {
+		t.Errorf("tags = %v, want only redacted secret", tags)
+	}
+	if fields := mockFields(spans[0]); !reflect.DeepEqual(fields, map[string]string{"secret": redacted}) {
+		t.Errorf("log fields = %v, want only redacted secret", fields)
+	}
+}
+
+func TestRulesTracer(t *testing.T) {
+	cfg, tracer := rulesAdapter(t, Config{
+		Redact: []string{"db.statement"},
+		Attributes: []AttributeRule{
+			{Key: "drop", Action: AttributeDrop},
+			{Key: "redact", Action: AttributeRedact},
+			{Key: "old", Action: AttributeRename, To: "new"},
+		},
+	})
+	start := time.Now()
+	s := cfg.tracer.StartSpan("span",
+		opentracing.Tags{"drop": 1, "old": 2, "keep": 3, "query": "SELECT 1"},
+		opentracing.StartTime(start),
+	)
+	if _, ok := s.Tracer().(*rulesTracer); !ok {
+		t.Errorf("Tracer() of span = %T, want rules tracer", s.Tracer())
+	}
+	s.SetTag("redact", "value")
+	s.SetTag("db.statement", "SELECT 2")
+	s.SetTag("drop", "again")
+	s.LogFields(log.String("old", "field"), log.Int("drop", 4))
+	s.LogKV("Query", "SELECT 3", "other", "kv")
+	s.FinishWithOptions(opentracing.FinishOptions{LogRecords: []opentracing.LogRecord{{
+		Timestamp: start,
+		Fields:    []log.Field{log.String("redact", "finish"), log.String("drop", "finish")},
+	}}})
+
+	spans := tracer.FinishedSpans()
+	if len(spans) != 1 {
+		t.Fatalf("finished spans = %d, want 1", len(spans))
+	}
+	if !spans[0].StartTime.Equal(start) {
+		t.Errorf("start time = %v, want %v", spans[0].StartTime, start)
+	}
+	wantTags := map[string]interface{}{
+		"new":          2,
+		"keep":         3,
+		"query":        redacted,
+		"redact":       redacted,
+		"db.statement": redacted,
+	}
+	if tags := spans[0].Tags(); !reflect.DeepEqual(tags, wantTags) {
+		t.Errorf("tags = %v, want %v", tags, wantTags)
+	}
+	wantFields := map[string]string{
+		"new":    "field",
+		"Query":  redacted,
+		"other":  "kv",
+		"redact": redacted,
+	}
+	if fields := mockFields(spans[0]); !reflect.DeepEqual(fields, wantFields) {
+		t.Errorf("log fields = %v, want %v", fields, wantFields)
+	}
+	if records := len(spans[0].Logs()); records != 3 {
+		t.Errorf("log records = %d, want 3", records)
+	}
+}
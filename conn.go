@@ -0,0 +1,170 @@
+package ydb
+
+import (
+	"strconv"
+	"sync"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/opentracing/opentracing-go/log"
+	"github.com/ydb-platform/ydb-go-sdk/v3/trace"
+)
+
+const (
+	connOperationName = "ydb.conn"
+
+	connIDTag          = "ydb.conn_id"
+	connLocationTag    = "ydb.location"
+	connTLSTag         = "ydb.tls"
+	connDialLatencyTag = "ydb.dial_latency_ms"
+	connDialsTag       = "ydb.dials"
+)
+
+type (
+	// connSpan lives from the first dial of connection to its close. Parking of
+	// connection and dialing it again are logged as events of the same span.
+	connSpan struct {
+		id    string
+		span  opentracing.Span
+		dials int
+	}
+	connSpans struct {
+		cfg *adapter
+
+		mu    sync.Mutex
+		seq   uint64
+		conns map[string]*connSpan
+	}
+)
+
+func (c *connSpans) get(endpoint trace.EndpointInfo) *connSpan {
+	if endpoint == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.conns[endpoint.Address()]
+}
+
+func (c *connSpans) dial(driver *driverTimeline, endpoint trace.EndpointInfo) *connSpan {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if conn, has := c.conns[endpoint.Address()]; has {
+		conn.dials++
+
+		return conn
+	}
+	c.seq++
+	conn := &connSpan{
+		id:    endpoint.Address() + "#" + strconv.FormatUint(c.seq, 10),
+		dials: 1,
+	}
+	opts := []opentracing.StartSpanOption{
+		opentracing.Tags{
+			connIDTag:               conn.id,
+			string(ext.PeerAddress): endpoint.Address(),
+			nodeIDTag:               strconv.FormatUint(uint64(endpoint.NodeID()), 10),
+			connLocationTag:         endpoint.Location(),
+			connTLSTag:              driver.isSecure(),
+			string(ext.PeerService): "ydb",
+		},
+	}
+	if parent := driver.context(); parent != nil {
+		opts = append(opts, opentracing.ChildOf(parent))
+	}
+	conn.span = c.cfg.tracer.StartSpan(connOperationName, append(opts, opentracing.StartTime(c.cfg.now()))...)
+	c.conns[endpoint.Address()] = conn
+
+	return conn
+}
+
+func (c *connSpans) close(endpoint trace.EndpointInfo, err error) {
+	c.mu.Lock()
+	conn, has := c.conns[endpoint.Address()]
+	delete(c.conns, endpoint.Address())
+	c.mu.Unlock()
+	if !has {
+		return
+	}
+	if err != nil {
+		setError(conn.span, err)
+	}
+	conn.span.SetTag(connDialsTag, conn.dials)
+	c.cfg.finish(conn.span)
+}
+
+// connections must be registered after spans.WithTraces: it tags request spans with connection identifier
+func connections(cfg *adapter, driver *driverTimeline) (t trace.Driver) {
+	conns := &connSpans{
+		cfg:   cfg,
+		conns: make(map[string]*connSpan),
+	}
+	t.OnConnDial = func(info trace.DriverConnDialStartInfo) func(trace.DriverConnDialDoneInfo) {
+		if cfg.Details()&trace.DriverConnEvents == 0 {
+			return nil
+		}
+		conn := conns.dial(driver, info.Endpoint)
+		conn.span.LogFields(log.String("event", "state"), log.String("state", "connecting"))
+		start := cfg.now()
+
+		return func(info trace.DriverConnDialDoneInfo) {
+			conn.span.SetTag(connDialLatencyTag, millis(cfg.now().Sub(start)))
+			if info.Error != nil {
+				conn.span.LogFields(log.String("event", "dial"), log.Error(info.Error))
+			}
+		}
+	}
+	t.OnConnStateChange = func(info trace.DriverConnStateChangeStartInfo) func(trace.DriverConnStateChangeDoneInfo) {
+		conn := conns.get(info.Endpoint)
+		if conn == nil {
+			return nil
+		}
+		from := info.State.String()
+
+		return func(info trace.DriverConnStateChangeDoneInfo) {
+			conn.span.LogFields(
+				log.String("event", "state"),
+				log.String("from", from),
+				log.String("state", info.State.String()),
+			)
+		}
+	}
+	t.OnConnBan = func(info trace.DriverConnBanStartInfo) func(trace.DriverConnBanDoneInfo) {
+		if conn := conns.get(info.Endpoint); conn != nil && info.Cause != nil {
+			conn.span.LogFields(log.String("event", "ban"), log.String("cause", info.Cause.Error()))
+		}
+
+		return nil
+	}
+	t.OnConnClose = func(info trace.DriverConnCloseStartInfo) func(trace.DriverConnCloseDoneInfo) {
+		endpoint := info.Endpoint
+		if endpoint == nil {
+			return nil
+		}
+
+		return func(info trace.DriverConnCloseDoneInfo) {
+			conns.close(endpoint, info.Error)
+		}
+	}
+	t.OnConnInvoke = func(info trace.DriverConnInvokeStartInfo) func(trace.DriverConnInvokeDoneInfo) {
+		if conn := conns.get(info.Endpoint); conn != nil {
+			if s := opentracing.SpanFromContext(*info.Context); s != nil {
+				s.SetTag(connIDTag, conn.id)
+			}
+		}
+
+		return nil
+	}
+	t.OnConnNewStream = func(info trace.DriverConnNewStreamStartInfo) func(trace.DriverConnNewStreamDoneInfo) {
+		if conn := conns.get(info.Endpoint); conn != nil {
+			if s := opentracing.SpanFromContext(*info.Context); s != nil {
+				s.SetTag(connIDTag, conn.id)
+			}
+		}
+
+		return nil
+	}
+
+	return t
+}
@@ -0,0 +1,130 @@
+package ydb
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/ydb-platform/ydb-go-genproto/protos/Ydb_Coordination"
+	"github.com/ydb-platform/ydb-go-sdk/v3/coordination"
+	"github.com/ydb-platform/ydb-go-sdk/v3/coordination/options"
+)
+
+const (
+	semaphoreAcquireOperationName = "ydb.coordination.semaphore.acquire"
+	semaphoreReleaseOperationName = "ydb.coordination.semaphore.release"
+
+	coordinationSessionIDTag = "ydb.coordination.session_id"
+	semaphoreNameTag         = "ydb.semaphore.name"
+	semaphoreCountTag        = "ydb.semaphore.count"
+	semaphoreEphemeralTag    = "ydb.semaphore.ephemeral"
+	semaphoreTimeoutTag      = "ydb.semaphore.timeout_ms"
+	semaphoreWaitTag         = "ydb.semaphore.wait_ms"
+	semaphoreHeldTag         = "ydb.semaphore.held_ms"
+	contendedTag             = "ydb.contended"
+)
+
+var (
+	_ coordination.Session = (*coordinationSession)(nil)
+	_ coordination.Lease   = (*coordinationLease)(nil)
+)
+
+type (
+	coordinationSession struct {
+		coordination.Session
+
+		cfg *adapter
+	}
+	coordinationLease struct {
+		coordination.Lease
+
+		session *coordinationSession
+		// ctx of caller, so release span is a sibling of acquire span
+		ctx      context.Context //nolint:containedctx
+		name     string
+		count    uint64
+		acquired time.Time
+	}
+)
+
+// WrapCoordinationSession wraps coordination service session and starts spans around acquire
+// and release of semaphores. Spans are tagged with semaphore name, count, ephemeral flag and
+// wait time. Acquire which waits longer than contention threshold (see WithContentionThreshold)
+// is tagged as contended.
+func WrapCoordinationSession(s coordination.Session, opts ...Option) coordination.Session {
+	return &coordinationSession{
+		Session: s,
+		cfg:     newAdapter(opts...),
+	}
+}
+
+func (s *coordinationSession) AcquireSemaphore(
+	ctx context.Context,
+	name string,
+	count uint64,
+	opts ...options.AcquireSemaphoreOption,
+) (coordination.Lease, error) {
+	req := Ydb_Coordination.SessionRequest_AcquireSemaphore{
+		TimeoutMillis: math.MaxUint64,
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&req)
+		}
+	}
+	tags := opentracing.Tags{
+		coordinationSessionIDTag: s.SessionID(),
+		semaphoreNameTag:         name,
+		semaphoreCountTag:        count,
+		semaphoreEphemeralTag:    req.GetEphemeral(),
+	}
+	if req.GetTimeoutMillis() != math.MaxUint64 {
+		tags[semaphoreTimeoutTag] = req.GetTimeoutMillis()
+	}
+	sp, acquireCtx := s.cfg.startSpanFromContext(ctx, semaphoreAcquireOperationName, tags)
+	defer s.cfg.finish(sp)
+
+	start := s.cfg.now()
+	lease, err := s.Session.AcquireSemaphore(acquireCtx, name, count, opts...)
+	wait := s.cfg.now().Sub(start)
+	sp.SetTag(semaphoreWaitTag, millis(wait))
+	sp.SetTag(contendedTag, wait >= s.cfg.contentionThreshold)
+	if err != nil {
+		setError(sp, err)
+
+		return nil, err
+	}
+
+	return &coordinationLease{
+		Lease:    lease,
+		session:  s,
+		ctx:      ctx,
+		name:     name,
+		count:    count,
+		acquired: s.cfg.now(),
+	}, nil
+}
+
+func (l *coordinationLease) Release() error {
+	sp, _ := l.session.cfg.startSpanFromContext(l.ctx, semaphoreReleaseOperationName,
+		opentracing.Tags{
+			coordinationSessionIDTag: l.session.SessionID(),
+			semaphoreNameTag:         l.name,
+			semaphoreCountTag:        l.count,
+			semaphoreHeldTag:         millis(l.session.cfg.now().Sub(l.acquired)),
+		},
+	)
+	defer l.session.cfg.finish(sp)
+
+	err := l.Lease.Release()
+	if err != nil {
+		setError(sp, err)
+	}
+
+	return err
+}
+
+func (l *coordinationLease) Session() coordination.Session {
+	return l.session
+}
@@ -0,0 +1,211 @@
+package ydb
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/ydb-platform/ydb-go-sdk/v3/trace"
+)
+
+const (
+	ddlOperationName = "ydb.ddl"
+
+	ddlTag        = "ydb.ddl"
+	ddlPathTag    = "ydb.ddl.path"
+	ddlKindTag    = "ydb.ddl.kind"
+	ddlServiceTag = "ydb.ddl.service"
+)
+
+// ddlStatement matches schema changing YQL statement after optional comments, pragmas and declares
+var ddlStatement = regexp.MustCompile(`(?is)^\s*(?:(?:--[^\n]*(?:\n|$)|/\*.*?\*/|(?:PRAGMA|DECLARE)\s[^;]*;)\s*)*` +
+	`(CREATE|ALTER|DROP)\s+(?:OR\s+REPLACE\s+)?((?:(?:TEMP|TEMPORARY|EXTERNAL)\s+)?\w+(?:\s+SOURCE)?)\s+` +
+	"(?:IF\\s+(?:NOT\\s+)?EXISTS\\s+)?(`[^`]+`|[\\w/.-]+)")
+
+type (
+	// DDLRecord is an audit record of schema changing operation
+	DDLRecord struct {
+		Service   string
+		Kind      string
+		Path      string
+		Statement string
+		Start     time.Time
+		Duration  time.Duration
+		Error     error
+	}
+	// DDL makes spans for schema changing operations which have no ydb-go-sdk trace events,
+	// such as table.Session.CreateTable, DropTable and AlterTable
+	DDL struct {
+		cfg *adapter
+	}
+)
+
+// ddlKind returns operation kind (CREATE TABLE, DROP TOPIC and so on) and object path of YQL statement
+func ddlKind(query string) (kind, path string, ok bool) {
+	m := ddlStatement.FindStringSubmatch(query)
+	if m == nil {
+		return "", "", false
+	}
+	kind = strings.ToUpper(m[1] + " " + strings.Join(strings.Fields(m[2]), " "))
+
+	return kind, strings.Trim(m[3], "`"), true
+}
+
+// NewDDL makes tracing of schema changing operations with given options (see WithDDLAudit and WithDDLSink)
+func NewDDL(opts ...Option) *DDL {
+	cfg := newAdapter(opts...)
+	cfg.ddlAudit = true
+
+	return &DDL{
+		cfg: cfg,
+	}
+}
+
+// Do calls op inside ydb.ddl span tagged with kind of operation and object path.
+// Kind is a verb with object type, for example "CREATE TABLE" or "DROP TABLE".
+func (d *DDL) Do(ctx context.Context, kind, path string, op func(ctx context.Context) error) error {
+	s, ctx := d.cfg.startSpanFromContext(ctx, ddlOperationName)
+	done := d.cfg.ddl(s, kind, path, "")
+	err := op(ctx)
+	if err != nil {
+		setError(s, err)
+	}
+	if done != nil {
+		done(err)
+	}
+	d.cfg.finish(s)
+
+	return err
+}
+
+// ddl tags span as schema changing operation and returns func which sends audit record to sink
+func (cfg *adapter) ddl(s opentracing.Span, kind, path, statement string) func(err error) {
+	if !cfg.ddlAudit {
+		return nil
+	}
+	ext.DBType.Set(s, "ydb")
+	s.SetTag(ddlTag, true)
+	s.SetTag(ddlKindTag, kind)
+	if path != "" {
+		s.SetTag(ddlPathTag, path)
+	}
+	if cfg.ddlService != "" {
+		s.SetTag(ddlServiceTag, cfg.ddlService)
+	}
+	if cfg.ddlSink == nil {
+		return nil
+	}
+	start := cfg.now()
+
+	return func(err error) {
+		cfg.ddlSink(DDLRecord{
+			Service:   cfg.ddlService,
+			Kind:      kind,
+			Path:      path,
+			Statement: cfg.encoding.statement(statement),
+			Start:     start,
+			Duration:  cfg.now().Sub(start),
+			Error:     err,
+		})
+	}
+}
+
+// ddlQuery tags span of statement started by spans package, so it must be registered after spans.WithTraces
+func (cfg *adapter) ddlQuery(ctx context.Context, query string) func(err error) {
+	if !cfg.ddlAudit {
+		return nil
+	}
+	kind, path, ok := ddlKind(query)
+	if !ok {
+		return nil
+	}
+	s := opentracing.SpanFromContext(ctx)
+	if s == nil {
+		return nil
+	}
+
+	return cfg.ddl(s, kind, path, query)
+}
+
+// scheme operations have no spans in spans package, so ydb.ddl spans are started for them
+func (cfg *adapter) ddlScheme(ctx *context.Context, functionID, kind, path string) func(err error) {
+	if !cfg.ddlAudit {
+		return nil
+	}
+	var s opentracing.Span
+	s, *ctx = cfg.startSpanFromContext(*ctx, functionID)
+	done := cfg.ddl(s, kind, path, "")
+
+	return func(err error) {
+		if err != nil {
+			setError(s, err)
+		}
+		if done != nil {
+			done(err)
+		}
+		cfg.finish(s)
+	}
+}
+
+func ddlScheme(cfg *adapter) (t trace.Scheme) {
+	t.OnMakeDirectory = func(info trace.SchemeMakeDirectoryStartInfo) func(trace.SchemeMakeDirectoryDoneInfo) {
+		done := cfg.ddlScheme(info.Context, info.Call.FunctionID(), "MAKE DIRECTORY", info.Path)
+		if done == nil {
+			return nil
+		}
+
+		return func(info trace.SchemeMakeDirectoryDoneInfo) {
+			done(info.Error)
+		}
+	}
+	t.OnRemoveDirectory = func(info trace.SchemeRemoveDirectoryStartInfo) func(trace.SchemeRemoveDirectoryDoneInfo) {
+		done := cfg.ddlScheme(info.Context, info.Call.FunctionID(), "REMOVE DIRECTORY", info.Path)
+		if done == nil {
+			return nil
+		}
+
+		return func(info trace.SchemeRemoveDirectoryDoneInfo) {
+			done(info.Error)
+		}
+	}
+	t.OnModifyPermissions = func(info trace.SchemeModifyPermissionsStartInfo) func(trace.SchemeModifyPermissionsDoneInfo) {
+		done := cfg.ddlScheme(info.Context, info.Call.FunctionID(), "MODIFY PERMISSIONS", info.Path)
+		if done == nil {
+			return nil
+		}
+
+		return func(info trace.SchemeModifyPermissionsDoneInfo) {
+			done(info.Error)
+		}
+	}
+
+	return t
+}
+
+func ddlQuery(cfg *adapter) (t trace.Query) {
+	t.OnExec = func(info trace.QueryExecStartInfo) func(trace.QueryExecDoneInfo) {
+		done := cfg.ddlQuery(*info.Context, info.Query)
+		if done == nil {
+			return nil
+		}
+
+		return func(info trace.QueryExecDoneInfo) {
+			done(info.Error)
+		}
+	}
+	t.OnSessionExec = func(info trace.QuerySessionExecStartInfo) func(trace.QuerySessionExecDoneInfo) {
+		done := cfg.ddlQuery(*info.Context, info.Query)
+		if done == nil {
+			return nil
+		}
+
+		return func(info trace.QuerySessionExecDoneInfo) {
+			done(info.Error)
+		}
+	}
+
+	return t
+}
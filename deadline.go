@@ -0,0 +1,40 @@
+package ydb
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/opentracing/opentracing-go"
+)
+
+const (
+	deadlineBudgetTag = "ydb.deadline_budget_ms"
+	contextErrorTag   = "ydb.context_error"
+	contextCauseTag   = "ydb.context_cause"
+)
+
+// deadlineTags tags starting span with time left to deadline of ctx. Deadline of context
+// is wall clock time, so budget is measured with time.Now, not with clock of adapter.
+func deadlineTags(ctx context.Context, tags opentracing.Tags) {
+	if deadline, has := ctx.Deadline(); has {
+		tags[deadlineBudgetTag] = millis(time.Until(deadline))
+	}
+}
+
+// contextTags tags span of call whose context is done with context error and cancellation cause,
+// which tells client timeouts and cancellations from server slowness
+func contextTags(ctx context.Context, s opentracing.Span) {
+	err := ctx.Err()
+	if err == nil {
+		return
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		s.SetTag(contextErrorTag, "deadline_exceeded")
+	} else {
+		s.SetTag(contextErrorTag, "canceled")
+	}
+	if cause := context.Cause(ctx); cause != nil && !errors.Is(err, cause) {
+		s.SetTag(contextCauseTag, cause.Error())
+	}
+}
@@ -0,0 +1,69 @@
+package ydb
+
+import (
+	"runtime"
+	"time"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/log"
+)
+
+const (
+	slowSpanEvent = "slow_span"
+
+	// stackSize limits stack dump of goroutine which ends slow span
+	stackSize = 64 << 10
+)
+
+// diagnostics captures runtime stats on spans which are longer than threshold (see WithSlowSpanDiagnostics)
+type diagnostics struct {
+	threshold time.Duration
+	stack     bool
+}
+
+// gcPauses sums GC pauses which ended within window. Runtime keeps last 256 pauses only.
+func gcPauses(stats *runtime.MemStats, start, end time.Time) (total time.Duration, count int) {
+	n := int(stats.NumGC)
+	if n > len(stats.PauseEnd) {
+		n = len(stats.PauseEnd)
+	}
+	for i := 0; i < n; i++ {
+		j := (int(stats.NumGC) - 1 - i) % len(stats.PauseEnd)
+		pauseEnd := time.Unix(0, int64(stats.PauseEnd[j]))
+		if pauseEnd.Before(start) {
+			break
+		}
+		if !pauseEnd.After(end) {
+			total += time.Duration(stats.PauseNs[j])
+			count++
+		}
+	}
+
+	return total, count
+}
+
+// capture logs runtime stats on span if it is longer than threshold. Stats are read
+// on slow path only: runtime.ReadMemStats stops the world.
+func (d *diagnostics) capture(s opentracing.Span, start time.Time) {
+	end := time.Now()
+	elapsed := end.Sub(start)
+	if elapsed < d.threshold {
+		return
+	}
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	pause, gcs := gcPauses(&stats, start, end)
+	fields := []log.Field{
+		log.String("event", slowSpanEvent),
+		log.Float64("duration_ms", millis(elapsed)),
+		log.Int("goroutines", runtime.NumGoroutine()),
+		log.Uint64("heap_inuse_bytes", stats.HeapInuse),
+		log.Float64("gc_pause_ms", millis(pause)),
+		log.Int("gc_count", gcs),
+	}
+	if d.stack {
+		buf := make([]byte, stackSize)
+		fields = append(fields, log.String("stack", string(buf[:runtime.Stack(buf, false)])))
+	}
+	s.LogFields(fields...)
+}
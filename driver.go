@@ -0,0 +1,276 @@
+package ydb
+
+import (
+	"sync"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/ydb-platform/ydb-go-sdk/v3/trace"
+)
+
+const (
+	driverOperationName = "ydb.driver"
+
+	driverEndpointTag = "ydb.endpoint"
+	driverDatabaseTag = "ydb.database"
+	driverSecureTag   = "ydb.secure"
+	driverBalancerTag = "ydb.balancer"
+	driverTraceIDTag  = "ydb.driver.trace_id"
+
+	discoveryOperationName        = "ydb.discovery"
+	discoveryAttemptOperationName = "ydb.discovery.attempt"
+	balancerUpdateOperationName   = "ydb.balancer.update"
+	connBanOperationName          = "ydb.conn.ban"
+	connUnbanOperationName        = "ydb.conn.unban"
+
+	eventAddressTag           = "ydb.address"
+	eventLocationTag          = "ydb.location"
+	eventEndpointsTag         = "ydb.endpoints"
+	balancerAddedTag          = "ydb.balancer.added"
+	balancerDroppedTag        = "ydb.balancer.dropped"
+	balancerLocalDCTag        = "ydb.balancer.local_dc"
+	balancerNeedLocalDCTag    = "ydb.balancer.need_local_dc"
+	balancerLocalEndpointsTag = "ydb.balancer.local_endpoints"
+	balancerDecisionTag       = "ydb.balancer.decision"
+	connStateTag              = "ydb.conn.state"
+	connCauseTag              = "ydb.conn.cause"
+)
+
+// driverTimeline is a long-lived span of driver from ydb.Open to Driver.Close.
+// Discovery rounds, balancer updates and connection bans are short spans which follow from it,
+// so driver span itself does not grow while driver lives.
+type driverTimeline struct {
+	cfg *adapter
+
+	mu     sync.Mutex
+	span   opentracing.Span
+	secure bool
+	// root are tags of implicit roots (see WithImplicitRoot)
+	root opentracing.Tags
+}
+
+func (d *driverTimeline) context() opentracing.SpanContext {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.span == nil {
+		return nil
+	}
+
+	return d.span.Context()
+}
+
+// rootTags tags span without parent with endpoint, database and trace of driver
+func (d *driverTimeline) rootTags(tags opentracing.Tags) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for k, v := range d.root {
+		tags[k] = v
+	}
+}
+
+func (d *driverTimeline) isSecure() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.secure
+}
+
+// startEvent starts span of driver event, which follows from driver span
+func (d *driverTimeline) startEvent(operationName string, tags opentracing.Tags) opentracing.Span {
+	opts := []opentracing.StartSpanOption{opentracing.StartTime(d.cfg.now()), tags}
+	if sc := d.context(); sc != nil {
+		opts = append(opts, opentracing.FollowsFrom(sc))
+	}
+
+	return d.cfg.tracer.StartSpan(operationName, opts...)
+}
+
+func (d *driverTimeline) finishEvent(s opentracing.Span, tags opentracing.Tags, err error) {
+	for k, v := range tags {
+		s.SetTag(k, v)
+	}
+	if err != nil {
+		setError(s, err)
+	}
+	d.cfg.finish(s)
+}
+
+func (d *driverTimeline) setTag(key string, value interface{}) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.span != nil {
+		d.span.SetTag(key, value)
+	}
+}
+
+func (d *driverTimeline) start(info trace.DriverInitStartInfo) {
+	opts := []opentracing.StartSpanOption{
+		opentracing.StartTime(d.cfg.now()),
+		opentracing.Tags{
+			string(ext.DBType):     "ydb",
+			string(ext.DBInstance): info.Database,
+			driverEndpointTag:      info.Endpoint,
+			driverDatabaseTag:      info.Database,
+			driverSecureTag:        info.Secure,
+		},
+	}
+	// driver outlives the caller of ydb.Open, so its span follows from caller span
+	if parent := opentracing.SpanFromContext(*info.Context); parent != nil {
+		opts = append(opts, opentracing.FollowsFrom(parent.Context()))
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.span = d.cfg.tracer.StartSpan(driverOperationName, opts...)
+	d.secure = info.Secure
+	d.root = opentracing.Tags{
+		driverEndpointTag: info.Endpoint,
+		driverDatabaseTag: info.Database,
+	}
+	if tc, ok := nativeContext(d.cfg.tracer, d.span.Context()); ok {
+		d.root[driverTraceIDTag] = tc.traceID
+	}
+}
+
+func (d *driverTimeline) finish(err error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.span == nil {
+		return
+	}
+	if err != nil {
+		setError(d.span, err)
+	}
+	d.cfg.finish(d.span)
+	d.span = nil
+}
+
+func endpointAddresses(endpoints []trace.EndpointInfo) []string {
+	addresses := make([]string, 0, len(endpoints))
+	for _, e := range endpoints {
+		addresses = append(addresses, e.Address()+"@"+e.Location())
+	}
+
+	return addresses
+}
+
+// localDCDecision describes how balancer chooses endpoints after discovery round
+func localDCDecision(needLocalDC bool, localDC string, endpoints []trace.EndpointInfo) (decision string, local int) {
+	for _, e := range endpoints {
+		if localDC != "" && e.Location() == localDC {
+			local++
+		}
+	}
+	switch {
+	case !needLocalDC:
+		return "any_dc", local
+	case local > 0:
+		return "local_dc", local
+	default:
+		return "fallback_all_dc", local
+	}
+}
+
+// timeline must be registered once per driver (WithTraces does it)
+//
+//nolint:funlen
+func timeline(cfg *adapter, driver *driverTimeline) (t trace.Driver, d trace.Discovery) {
+	t.OnInit = func(info trace.DriverInitStartInfo) func(trace.DriverInitDoneInfo) {
+		driver.start(info)
+
+		return func(info trace.DriverInitDoneInfo) {
+			if info.Error != nil {
+				driver.finish(info.Error)
+			}
+		}
+	}
+	t.OnClose = func(info trace.DriverCloseStartInfo) func(trace.DriverCloseDoneInfo) {
+		return func(info trace.DriverCloseDoneInfo) {
+			driver.finish(info.Error)
+		}
+	}
+	t.OnBalancerInit = func(info trace.DriverBalancerInitStartInfo) func(trace.DriverBalancerInitDoneInfo) {
+		driver.setTag(driverBalancerTag, info.Name)
+
+		return nil
+	}
+	t.OnBalancerClusterDiscoveryAttempt = func(
+		info trace.DriverBalancerClusterDiscoveryAttemptStartInfo,
+	) func(trace.DriverBalancerClusterDiscoveryAttemptDoneInfo) {
+		if cfg.Details()&trace.DriverBalancerEvents == 0 {
+			return nil
+		}
+		s := driver.startEvent(discoveryAttemptOperationName, opentracing.Tags{
+			eventAddressTag: info.Address,
+		})
+
+		return func(info trace.DriverBalancerClusterDiscoveryAttemptDoneInfo) {
+			driver.finishEvent(s, nil, info.Error)
+		}
+	}
+	t.OnBalancerUpdate = func(info trace.DriverBalancerUpdateStartInfo) func(trace.DriverBalancerUpdateDoneInfo) {
+		if cfg.Details()&trace.DriverBalancerEvents == 0 {
+			return nil
+		}
+		needLocalDC := info.NeedLocalDC
+		s := driver.startEvent(balancerUpdateOperationName, opentracing.Tags{
+			balancerNeedLocalDCTag: needLocalDC,
+		})
+
+		return func(info trace.DriverBalancerUpdateDoneInfo) {
+			decision, local := localDCDecision(needLocalDC, info.LocalDC, info.Endpoints)
+			driver.finishEvent(s, opentracing.Tags{
+				eventEndpointsTag:         len(info.Endpoints),
+				balancerAddedTag:          endpointAddresses(info.Added),
+				balancerDroppedTag:        endpointAddresses(info.Dropped),
+				balancerLocalDCTag:        info.LocalDC,
+				balancerLocalEndpointsTag: local,
+				balancerDecisionTag:       decision,
+			}, nil)
+		}
+	}
+	t.OnConnBan = func(info trace.DriverConnBanStartInfo) func(trace.DriverConnBanDoneInfo) {
+		if cfg.Details()&trace.DriverConnEvents == 0 {
+			return nil
+		}
+		tags := opentracing.Tags{
+			eventAddressTag:  info.Endpoint.Address(),
+			eventLocationTag: info.Endpoint.Location(),
+			connStateTag:     info.State.String(),
+		}
+		if info.Cause != nil {
+			tags[connCauseTag] = info.Cause.Error()
+		}
+		driver.finishEvent(driver.startEvent(connBanOperationName, tags), nil, nil)
+
+		return nil
+	}
+	t.OnConnAllow = func(info trace.DriverConnAllowStartInfo) func(trace.DriverConnAllowDoneInfo) {
+		if cfg.Details()&trace.DriverConnEvents == 0 {
+			return nil
+		}
+		driver.finishEvent(driver.startEvent(connUnbanOperationName, opentracing.Tags{
+			eventAddressTag:  info.Endpoint.Address(),
+			eventLocationTag: info.Endpoint.Location(),
+			connStateTag:     info.State.String(),
+		}), nil, nil)
+
+		return nil
+	}
+	d.OnDiscover = func(info trace.DiscoveryDiscoverStartInfo) func(trace.DiscoveryDiscoverDoneInfo) {
+		if cfg.Details()&trace.DiscoveryEvents == 0 {
+			return nil
+		}
+		s := driver.startEvent(discoveryOperationName, opentracing.Tags{
+			eventAddressTag: info.Address,
+		})
+
+		return func(info trace.DiscoveryDiscoverDoneInfo) {
+			driver.finishEvent(s, opentracing.Tags{
+				eventLocationTag:  info.Location,
+				eventEndpointsTag: endpointAddresses(info.Endpoints),
+			}, info.Error)
+		}
+	}
+
+	return t, d
+}
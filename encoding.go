@@ -0,0 +1,128 @@
+package ydb
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/opentracing/opentracing-go/log"
+)
+
+type (
+	// DurationEncoding is a representation of time.Duration values of span fields
+	DurationEncoding int
+	// BytesEncoding is a representation of []byte values of span fields
+	BytesEncoding int
+	// TimeEncoding is a representation of time.Time values of span fields
+	TimeEncoding int
+
+	encoding struct {
+		duration DurationEncoding
+		bytes    BytesEncoding
+		time     TimeEncoding
+		// rules of attributes by key (see Config)
+		rules map[string]AttributeRule
+	}
+)
+
+const (
+	// DurationMilliseconds encodes duration as float number of milliseconds (default)
+	DurationMilliseconds DurationEncoding = iota
+	// DurationNanoseconds encodes duration as integer number of nanoseconds
+	DurationNanoseconds
+	// DurationString encodes duration as string like 1.5s
+	DurationString
+)
+
+const (
+	// BytesHex encodes bytes as hex string (default)
+	BytesHex BytesEncoding = iota
+	// BytesBase64 encodes bytes as standard base64 string
+	BytesBase64
+)
+
+const (
+	// TimeRFC3339 encodes time as RFC 3339 string with nanoseconds (default)
+	TimeRFC3339 TimeEncoding = iota
+	// TimeUnixMillis encodes time as integer number of milliseconds since Unix epoch
+	TimeUnixMillis
+)
+
+// encode converts value of field which is not of basic type to value supported by tracers:
+// numbers, strings and booleans
+func (e *encoding) encode(v interface{}) interface{} {
+	switch v := v.(type) {
+	case time.Duration:
+		switch e.duration {
+		case DurationNanoseconds:
+			return v.Nanoseconds()
+		case DurationString:
+			return v.String()
+		default:
+			return millis(v)
+		}
+	case time.Time:
+		if e.time == TimeUnixMillis {
+			return v.UnixMilli()
+		}
+
+		return v.Format(time.RFC3339Nano)
+	case error:
+		return v.Error()
+	case []byte:
+		if e.bytes == BytesBase64 {
+			return base64.StdEncoding.EncodeToString(v)
+		}
+
+		return hex.EncodeToString(v)
+	case []int, []int64, [][]int, [][]int64:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+
+		return string(b)
+	case fmt.Stringer:
+		return v.String()
+	default:
+		return v
+	}
+}
+
+// attribute makes log field of type matched to encoded value
+func (e *encoding) attribute(key string, v interface{}) log.Field {
+	switch v := e.encode(v).(type) {
+	case int:
+		return log.Int(key, v)
+	case int64:
+		return log.Int64(key, v)
+	case float64:
+		return log.Float64(key, v)
+	case float32:
+		return log.Float32(key, v)
+	case string:
+		return log.String(key, v)
+	case bool:
+		return log.Bool(key, v)
+	default:
+		return log.Object(key, v)
+	}
+}
+
+// apply applies attribute rule to key and value, ok is false if attribute is dropped
+func (e *encoding) apply(key string, v interface{}) (_ string, _ interface{}, ok bool) {
+	r, has := e.rules[key]
+	if !has {
+		return key, v, true
+	}
+	switch r.Action {
+	case AttributeDrop:
+		return "", nil, false
+	case AttributeRedact:
+		return key, redacted, true
+	default:
+		return r.To, v, true
+	}
+}
@@ -0,0 +1,131 @@
+package ydb
+
+import (
+	"errors"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/opentracing/opentracing-go/log"
+)
+
+// spans.KeyValue can be made only by ydb-go-sdk, so values of fields which are not of basic type
+// are tested with encode and attribute, which fieldValue and fieldToAttribute delegate to
+
+func TestEncodeDuration(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		encoding DurationEncoding
+		v        time.Duration
+		want     interface{}
+	}{
+		{"milliseconds", DurationMilliseconds, 1500 * time.Microsecond, 1.5},
+		{"milliseconds zero", DurationMilliseconds, 0, 0.0},
+		{"nanoseconds", DurationNanoseconds, 1500 * time.Microsecond, int64(1500000)},
+		{"string", DurationString, 1500 * time.Millisecond, "1.5s"},
+		{"negative", DurationMilliseconds, -2 * time.Millisecond, -2.0},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			e := encoding{duration: tt.encoding}
+			if got := e.encode(tt.v); got != tt.want {
+				t.Errorf("encode(%v) = %#v, want %#v", tt.v, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEncodeTime(t *testing.T) {
+	ts := time.Date(2024, 2, 3, 4, 5, 6, 7000000, time.UTC)
+	for _, tt := range []struct {
+		name     string
+		encoding TimeEncoding
+		v        time.Time
+		want     interface{}
+	}{
+		{"rfc3339", TimeRFC3339, ts, "2024-02-03T04:05:06.007Z"},
+		{"rfc3339 offset", TimeRFC3339, ts.In(time.FixedZone("", 3*60*60)), "2024-02-03T07:05:06.007+03:00"},
+		{"unix millis", TimeUnixMillis, ts, ts.UnixMilli()},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			e := encoding{time: tt.encoding}
+			if got := e.encode(tt.v); got != tt.want {
+				t.Errorf("encode(%v) = %#v, want %#v", tt.v, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEncodeBytes(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		encoding BytesEncoding
+		v        []byte
+		want     interface{}
+	}{
+		{"hex", BytesHex, []byte{0x00, 0xab, 0xff}, "00abff"},
+		{"hex empty", BytesHex, []byte{}, ""},
+		{"base64", BytesBase64, []byte("ydb"), "eWRi"},
+		{"base64 padding", BytesBase64, []byte{0xff}, "/w=="},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			e := encoding{bytes: tt.encoding}
+			if got := e.encode(tt.v); got != tt.want {
+				t.Errorf("encode(%v) = %#v, want %#v", tt.v, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEncodeValues(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		v    interface{}
+		want interface{}
+	}{
+		{"error", errors.New("boom"), "boom"},
+		{"ints", []int{1, 2}, "[1,2]"},
+		{"int64s", []int64{3}, "[3]"},
+		{"int matrix", [][]int{{1, 2}, {3}}, "[[1,2],[3]]"},
+		{"int64 matrix", [][]int64{{4}, {}}, "[[4],[]]"},
+		{"nil ints", []int(nil), "null"},
+		{"stringer", net.IPv4(127, 0, 0, 1), "127.0.0.1"},
+		{"int", 1, 1},
+		{"string", "s", "s"},
+		{"bool", true, true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var e encoding
+			if got := e.encode(tt.v); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("encode(%v) = %#v, want %#v", tt.v, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAttribute(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		encoding encoding
+		v        interface{}
+		want     log.Field
+	}{
+		{"duration milliseconds", encoding{}, time.Millisecond, log.Float64("key", 1)},
+		{"duration nanoseconds", encoding{duration: DurationNanoseconds}, time.Millisecond, log.Int64("key", 1000000)},
+		{"duration string", encoding{duration: DurationString}, time.Second, log.String("key", "1s")},
+		{"time", encoding{time: TimeUnixMillis}, time.UnixMilli(42), log.Int64("key", 42)},
+		{"error", encoding{}, errors.New("boom"), log.String("key", "boom")},
+		{"bytes", encoding{}, []byte{0x01}, log.String("key", "01")},
+		{"int matrix", encoding{}, [][]int{{1}}, log.String("key", "[[1]]")},
+		{"int", encoding{}, 1, log.Int("key", 1)},
+		{"float32", encoding{}, float32(0.5), log.Float32("key", 0.5)},
+		{"bool", encoding{}, true, log.Bool("key", true)},
+		{"object", encoding{}, struct{ A int }{1}, log.Object("key", struct{ A int }{1})},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.encoding.attribute("key", tt.v); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("attribute(%v) = %v, want %v", tt.v, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package ydb
+
+import (
+	"context"
+	"errors"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/opentracing/opentracing-go/log"
+	"github.com/ydb-platform/ydb-go-genproto/protos/Ydb"
+	"github.com/ydb-platform/ydb-go-sdk/v3"
+	"github.com/ydb-platform/ydb-go-sdk/v3/retry"
+	grpcCodes "google.golang.org/grpc/codes"
+)
+
+const (
+	errorTypeTag              = "ydb.error_type"
+	statusTag                 = "ydb.status"
+	grpcCodeTag               = "ydb.grpc_code"
+	retryableTag              = "ydb.retryable"
+	retryableNonIdempotentTag = "ydb.retryable_non_idempotent"
+	deleteSessionTag          = "ydb.delete_session"
+
+	errorTypeOperation = "operation"
+	errorTypeTransport = "transport"
+	errorTypeContext   = "context"
+	errorTypeOther     = "other"
+)
+
+// errorTags classifies err: transport or operation error, YDB status or gRPC code,
+// retryability for idempotent and non-idempotent operations, and whether session must be deleted
+func errorTags(s opentracing.Span, err error) {
+	switch {
+	case ydb.IsOperationError(err):
+		s.SetTag(errorTypeTag, errorTypeOperation)
+		s.SetTag(statusTag, Ydb.StatusIds_StatusCode(ydb.OperationError(err).Code()).String())
+	case ydb.IsTransportError(err):
+		s.SetTag(errorTypeTag, errorTypeTransport)
+		s.SetTag(grpcCodeTag, grpcCodes.Code(ydb.TransportError(err).Code()).String())
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		s.SetTag(errorTypeTag, errorTypeContext)
+	default:
+		s.SetTag(errorTypeTag, errorTypeOther)
+	}
+	m := retry.Check(err)
+	s.SetTag(retryableTag, m.MustRetry(true))
+	s.SetTag(retryableNonIdempotentTag, m.MustRetry(false))
+	s.SetTag(deleteSessionTag, m.MustDeleteSession())
+}
+
+// setError marks span as failed, tags it with classification of err and logs err
+func setError(s opentracing.Span, err error) {
+	ext.Error.Set(s, true)
+	errorTags(s, err)
+	s.LogFields(log.Error(err))
+}
@@ -0,0 +1,123 @@
+package ydb
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"testing"
+	"time"
+
+	"github.com/ydb-platform/ydb-go-sdk/v3"
+	"github.com/ydb-platform/ydb-go-sdk/v3/query"
+	"github.com/ydb-platform/ydb-go-sdk/v3/table"
+	"github.com/ydb-platform/ydb-go-sdk/v3/trace"
+
+	"github.com/ydb-platform/ydb-go-sdk-opentracing/internal/stub"
+	"github.com/ydb-platform/ydb-go-sdk-opentracing/spantest"
+)
+
+// update rewrites golden file, run it after upgrade of ydb-go-sdk: go test -run TestGolden -update
+var update = flag.Bool("update", false, "rewrite golden file")
+
+const goldenDetails = trace.QueryEvents | trace.TableEvents | trace.RetryEvents | trace.DriverEvents |
+	trace.DatabaseSQLEvents
+
+// goldenQuery touches query, retry and query transaction spans
+func goldenQuery(ctx context.Context, db *ydb.Driver) error {
+	if err := db.Query().Exec(ctx, "SELECT 1"); err != nil {
+		return err
+	}
+	if err := db.Query().Do(ctx, func(ctx context.Context, s query.Session) error {
+		return s.Exec(ctx, "SELECT 2")
+	}, query.WithIdempotent()); err != nil {
+		return err
+	}
+
+	return db.Query().DoTx(ctx, Attempts(func(ctx context.Context, tx query.TxActor) error {
+		return tx.Exec(ctx, "UPSERT INTO t (id) VALUES (3)")
+	}), query.WithIdempotent())
+}
+
+// goldenTable touches table and table transaction spans
+func goldenTable(ctx context.Context, db *ydb.Driver) error {
+	return db.Table().DoTx(ctx, Attempts(func(ctx context.Context, tx table.TransactionActor) error {
+		_, err := tx.Execute(ctx, "UPSERT INTO t (id) VALUES (4)", nil)
+
+		return err
+	}), table.WithIdempotent())
+}
+
+// goldenSQL touches database/sql spans of connection, transaction and prepared statement
+func goldenSQL(ctx context.Context, db *ydb.Driver, tracer *spantest.Tracer) error {
+	connector, err := ydb.Connector(db)
+	if err != nil {
+		return err
+	}
+	sqlDB := OpenDB(connector, WithTracer(tracer))
+	defer func() {
+		_ = sqlDB.Close()
+	}()
+	if _, err = sqlDB.ExecContext(ctx, "UPSERT INTO t (id) VALUES (5)"); err != nil {
+		return err
+	}
+	tx, err := sqlDB.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return err
+	}
+	if _, err = tx.ExecContext(ctx, "UPSERT INTO t (id) VALUES (6)"); err != nil {
+		_ = tx.Rollback()
+
+		return err
+	}
+	if err = tx.Commit(); err != nil {
+		return err
+	}
+	stmt, err := sqlDB.PrepareContext(ctx, "UPSERT INTO t (id) VALUES (7)")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = stmt.Close()
+	}()
+	_, err = stmt.ExecContext(ctx)
+
+	return err
+}
+
+func TestGolden(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	s, err := stub.Start()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Stop()
+
+	tracer := spantest.New()
+	db, err := ydb.Open(ctx, s.Endpoint(),
+		ydb.WithAnonymousCredentials(),
+		WithTraces(
+			WithTracer(tracer),
+			WithDetailer(goldenDetails),
+		),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = goldenQuery(ctx, db); err == nil {
+		if err = goldenTable(ctx, db); err == nil {
+			err = goldenSQL(ctx, db, tracer)
+		}
+	}
+	if err != nil {
+		_ = db.Close(ctx)
+		t.Fatal(err)
+	}
+	if err = db.Close(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err = spantest.CompareGolden("testdata/spans.golden", tracer.Snapshot(nil), *update); err != nil {
+		t.Error(err)
+	}
+}
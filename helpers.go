@@ -1,13 +1,76 @@
 package ydb
 
 import (
-	"fmt"
+	"sync"
 
+	"github.com/opentracing/opentracing-go"
 	"github.com/opentracing/opentracing-go/log"
 	"github.com/ydb-platform/ydb-go-sdk/v3/spans"
 )
 
-func fieldToAttribute(field spans.KeyValue) log.Field {
+// tagsPool keeps intermediate tags of starting spans. Tracers copy tags into their own storage
+// on start (opentracing.Tags.Apply makes a new map), so pool saves only the map of adapter.
+var tagsPool = sync.Pool{
+	New: func() interface{} {
+		return make(opentracing.Tags, 8)
+	},
+}
+
+func getTags() opentracing.Tags {
+	return tagsPool.Get().(opentracing.Tags) //nolint:forcetypeassert
+}
+
+func putTags(tags opentracing.Tags) {
+	for k := range tags {
+		delete(tags, k)
+	}
+	tagsPool.Put(tags)
+}
+
+// sampled reports whether span with given context is recorded. Tracers without
+// sampling flag in span context (mocktracer, for example) record all spans.
+func sampled(tracer opentracing.Tracer, sc opentracing.SpanContext) bool {
+	if rules, ok := tracer.(*rulesTracer); ok {
+		tracer = rules.Tracer
+	}
+	if _, noop := tracer.(opentracing.NoopTracer); noop {
+		return false
+	}
+	if s, ok := sc.(interface{ IsSampled() bool }); ok {
+		return s.IsSampled()
+	}
+
+	return true
+}
+
+func (e *encoding) fieldValue(field spans.KeyValue) interface{} {
+	switch field.Type() {
+	case spans.IntType:
+		return field.IntValue()
+	case spans.Int64Type:
+		return field.Int64Value()
+	case spans.StringType:
+		return field.StringValue()
+	case spans.BoolType:
+		return field.BoolValue()
+	case spans.StringsType:
+		return field.StringsValue()
+	case spans.StringerType:
+		// tracers convert Stringer when span is reported, so unsampled and dropped spans
+		// never call String
+		return field.Stringer()
+	default:
+		return e.encode(field.AnyValue())
+	}
+}
+
+func (e *encoding) fieldsToTags(tags opentracing.Tags, fields []spans.KeyValue) {
+	for _, field := range fields {
+		tags[field.Key()] = e.fieldValue(field)
+	}
+}
+
+func (e *encoding) fieldToAttribute(field spans.KeyValue) log.Field {
 	switch field.Type() {
 	case spans.IntType:
 		return log.Int(field.Key(), field.IntValue())
@@ -20,18 +83,19 @@ func fieldToAttribute(field spans.KeyValue) log.Field {
 	case spans.StringsType:
 		return log.Object(field.Key(), field.StringsValue())
 	case spans.StringerType:
-		return log.String(field.Key(), field.Stringer().String())
+		return log.Object(field.Key(), field.Stringer())
 	default:
-		return log.String(field.Key(), fmt.Sprintf("%v", field.AnyValue()))
+		return e.attribute(field.Key(), field.AnyValue())
 	}
 }
 
-func fieldsToFields(fields []spans.KeyValue) []log.Field {
-	attributes := make([]log.Field, 0, len(fields))
-
+// fieldsToFields converts fields with extra capacity for event field of log record.
+// Log records are kept by tracers until report, so fields are not pooled.
+func (e *encoding) fieldsToFields(fields []spans.KeyValue, extra ...log.Field) []log.Field {
+	attributes := make([]log.Field, 0, len(fields)+len(extra))
 	for _, kv := range fields {
-		attributes = append(attributes, fieldToAttribute(kv))
+		attributes = append(attributes, e.fieldToAttribute(kv))
 	}
 
-	return attributes
+	return append(attributes, extra...)
 }
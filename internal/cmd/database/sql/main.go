@@ -2,7 +2,6 @@ package main
 
 import (
 	"context"
-	"database/sql"
 	"log"
 	"net/http"
 	"os"
@@ -14,7 +13,6 @@ import (
 	jaegerConfig "github.com/uber/jaeger-client-go/config"
 	"github.com/ydb-platform/ydb-go-sdk/v3"
 	"github.com/ydb-platform/ydb-go-sdk/v3/sugar"
-	"github.com/ydb-platform/ydb-go-sdk/v3/trace"
 
 	ydbTracing "github.com/ydb-platform/ydb-go-sdk-opentracing"
 )
@@ -30,6 +28,15 @@ func init() {
 }
 
 func main() {
+	// tracing is configured with file from YDB_TRACING_CONFIG and YDB_TRACING_* variables
+	config, err := ydbTracing.LoadConfig("")
+	if err != nil {
+		log.Fatalf("tracing config error: %v", err)
+	}
+	opts, err := config.Options()
+	if err != nil {
+		log.Fatalf("tracing config error: %v", err)
+	}
 	tracer, closer, err := jaegerConfig.Configuration{
 		ServiceName: serviceName,
 		Sampler: &jaegerConfig.SamplerConfig{
@@ -57,10 +64,10 @@ func main() {
 	nativeDriver, err := ydb.Open(ctx, os.Getenv("YDB_CONNECTION_STRING"),
 		ydb.WithDiscoveryInterval(5*time.Second),
 		ydb.WithIgnoreTruncated(),
-		ydbTracing.WithTraces(
-			ydbTracing.WithDetailer(trace.DetailsAll),
+		ydbTracing.WithTraces(append(opts,
 			ydbTracing.WithTracer(tracer),
-		),
+			ydbTracing.WithDDLAudit("database/sql"),
+		)...),
 	)
 	if err != nil {
 		log.Fatalf("connect error: %v", err)
@@ -72,7 +79,7 @@ func main() {
 		log.Fatalf("create connector failed: %v", err)
 	}
 
-	db := sql.OpenDB(connector)
+	db := ydbTracing.OpenDB(connector, append(opts, ydbTracing.WithTracer(tracer), ydbTracing.WithQueryComments())...)
 	defer func() { _ = db.Close() }()
 
 	ctx, cancel := context.WithCancel(context.Background())
@@ -14,6 +14,8 @@ import (
 	"github.com/ydb-platform/ydb-go-sdk/v3/sugar"
 	"github.com/ydb-platform/ydb-go-sdk/v3/table"
 	"github.com/ydb-platform/ydb-go-sdk/v3/table/types"
+
+	ydbTracing "github.com/ydb-platform/ydb-go-sdk-opentracing"
 )
 
 func sliceToInterfaces[T any](v []T) []interface{} {
@@ -43,7 +45,7 @@ func selectDefault(ctx context.Context, db *sql.DB) (err error) {
 	if err != nil {
 		return fmt.Errorf("explain query failed: %w", err)
 	}
-	err = retry.Do(ydb.WithTxControl(ctx, table.OnlineReadOnlyTxControl()), db, func(ctx context.Context, cc *sql.Conn) (err error) {
+	err = retry.Do(ydbTracing.WithTxControl(ctx, table.OnlineReadOnlyTxControl()), db, func(ctx context.Context, cc *sql.Conn) (err error) {
 		rows, err := cc.QueryContext(ctx, `
 			SELECT series_id, title, release_date FROM series;
 		`)
@@ -79,7 +81,7 @@ func selectDefault(ctx context.Context, db *sql.DB) (err error) {
 func selectScan(ctx context.Context, db *sql.DB) (err error) {
 	// scan query
 	err = retry.Do(
-		ydb.WithTxControl(ctx, table.StaleReadOnlyTxControl()), db,
+		ydbTracing.WithTxControl(ctx, table.StaleReadOnlyTxControl()), db,
 		func(ctx context.Context, cc *sql.Conn) (err error) {
 			var (
 				id        string
@@ -172,7 +174,7 @@ func fillTablesWithData(ctx context.Context, db *sql.DB) (err error) {
 		sql.Named("seasonsData", types.ListValue(seasonsData...)),
 		sql.Named("episodesData", types.ListValue(episodesData...)),
 	}
-	err = retry.DoTx(ctx, db, func(ctx context.Context, tx *sql.Tx) error {
+	err = retry.DoTx(ctx, db, ydbTracing.Attempts(func(ctx context.Context, tx *sql.Tx) error {
 		if _, err = tx.ExecContext(ctx, `
 				REPLACE INTO series
 				SELECT * FROM AS_TABLE($seriesData);
@@ -187,7 +189,7 @@ func fillTablesWithData(ctx context.Context, db *sql.DB) (err error) {
 			return err
 		}
 		return nil
-	}, retry.WithIdempotent(true))
+	}), retry.WithIdempotent(true))
 	if err != nil {
 		return fmt.Errorf("upsert query failed: %w", err)
 	}
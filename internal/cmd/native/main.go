@@ -21,7 +21,6 @@ import (
 	"github.com/ydb-platform/ydb-go-sdk/v3/table/options"
 	"github.com/ydb-platform/ydb-go-sdk/v3/table/result"
 	"github.com/ydb-platform/ydb-go-sdk/v3/table/types"
-	"github.com/ydb-platform/ydb-go-sdk/v3/trace"
 
 	tracing "github.com/ydb-platform/ydb-go-sdk-opentracing"
 )
@@ -45,6 +44,15 @@ func initJaeger(service string) (opentracing.Tracer, io.Closer) {
 }
 
 func main() {
+	// tracing is configured with file from YDB_TRACING_CONFIG and YDB_TRACING_* variables
+	config, err := tracing.LoadConfig("")
+	if err != nil {
+		panic(err)
+	}
+	opts, err := config.Options()
+	if err != nil {
+		panic(err)
+	}
 	tracer, closer := initJaeger("test")
 	defer closer.Close()
 	opentracing.SetGlobalTracer(tracer)
@@ -67,10 +75,10 @@ func main() {
 		creds,
 		ydb.WithSessionPoolSizeLimit(300),
 		ydb.WithSessionPoolIdleThreshold(time.Second*5),
-		tracing.WithTraces(
+		tracing.WithTraces(append(opts,
 			tracing.WithTracer(tracer),
-			tracing.WithDetailer(trace.DetailsAll),
-		),
+			tracing.WithDDLAudit("native"),
+		)...),
 	)
 	if err != nil {
 		panic(err)
@@ -111,9 +119,14 @@ func main() {
 }
 
 func upsertData(ctx context.Context, c table.Client, prefix, tableName string, concurrency int) (err error) {
+	ddl := tracing.NewDDL(tracing.WithDDLAudit("native"))
 	err = c.Do(ctx,
 		func(ctx context.Context, s table.Session) (err error) {
-			return s.DropTable(ctx, path.Join(prefix, tableName))
+			return ddl.Do(ctx, "DROP TABLE", path.Join(prefix, tableName),
+				func(ctx context.Context) error {
+					return s.DropTable(ctx, path.Join(prefix, tableName))
+				},
+			)
 		},
 		table.WithIdempotent(),
 	)
@@ -122,13 +135,17 @@ func upsertData(ctx context.Context, c table.Client, prefix, tableName string, c
 	}
 	err = c.Do(ctx,
 		func(ctx context.Context, s table.Session) (err error) {
-			return s.CreateTable(ctx, path.Join(prefix, tableName),
-				options.WithColumn("series_id", types.Optional(types.TypeUint64)),
-				options.WithColumn("title", types.Optional(types.TypeUTF8)),
-				options.WithColumn("series_info", types.Optional(types.TypeUTF8)),
-				options.WithColumn("release_date", types.Optional(types.TypeDate)),
-				options.WithColumn("comment", types.Optional(types.TypeUTF8)),
-				options.WithPrimaryKeyColumn("series_id"),
+			return ddl.Do(ctx, "CREATE TABLE", path.Join(prefix, tableName),
+				func(ctx context.Context) error {
+					return s.CreateTable(ctx, path.Join(prefix, tableName),
+						options.WithColumn("series_id", types.Optional(types.TypeUint64)),
+						options.WithColumn("title", types.Optional(types.TypeUTF8)),
+						options.WithColumn("series_info", types.Optional(types.TypeUTF8)),
+						options.WithColumn("release_date", types.Optional(types.TypeDate)),
+						options.WithColumn("comment", types.Optional(types.TypeUTF8)),
+						options.WithPrimaryKeyColumn("series_id"),
+					)
+				},
 			)
 		},
 		table.WithIdempotent(),
@@ -186,7 +203,7 @@ func scanSelect(ctx context.Context, c table.Client, prefix string, limit int64)
 		limit,
 	)
 	err = c.Do(ctx,
-		func(ctx context.Context, s table.Session) error {
+		tracing.Attempts(func(ctx context.Context, s table.Session) error {
 			var res result.StreamResult
 			count = 0
 			res, err = s.StreamExecuteScanQuery(
@@ -220,7 +237,7 @@ func scanSelect(ctx context.Context, c table.Client, prefix string, limit int64)
 				}
 			}
 			return res.Err()
-		},
+		}),
 		table.WithIdempotent(),
 	)
 	return
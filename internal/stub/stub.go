@@ -0,0 +1,173 @@
+// Package stub is a local YDB server for benchmarks and span snapshots.
+package stub
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"sync/atomic"
+
+	"github.com/ydb-platform/ydb-go-genproto/Ydb_Discovery_V1"
+	"github.com/ydb-platform/ydb-go-genproto/Ydb_Query_V1"
+	"github.com/ydb-platform/ydb-go-genproto/Ydb_Table_V1"
+	"github.com/ydb-platform/ydb-go-genproto/protos/Ydb"
+	"github.com/ydb-platform/ydb-go-genproto/protos/Ydb_Discovery"
+	"github.com/ydb-platform/ydb-go-genproto/protos/Ydb_Operations"
+	"github.com/ydb-platform/ydb-go-genproto/protos/Ydb_Query"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// Stub is a local gRPC server with discovery, query and table services, which answer
+// every request with success and empty result
+type Stub struct {
+	Ydb_Discovery_V1.UnimplementedDiscoveryServiceServer
+	Ydb_Query_V1.UnimplementedQueryServiceServer
+
+	host     string
+	port     uint32
+	server   *grpc.Server
+	sessions atomic.Int64
+	txs      atomic.Int64
+}
+
+// Start listens on random local port and serves in background
+func Start() (*Stub, error) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	addr := lis.Addr().(*net.TCPAddr) //nolint:forcetypeassert
+	s := &Stub{
+		host:   addr.IP.String(),
+		port:   uint32(addr.Port),
+		server: grpc.NewServer(),
+	}
+	Ydb_Discovery_V1.RegisterDiscoveryServiceServer(s.server, s)
+	Ydb_Query_V1.RegisterQueryServiceServer(s.server, s)
+	Ydb_Table_V1.RegisterTableServiceServer(s.server, &tableService{stub: s})
+	go func() {
+		_ = s.server.Serve(lis)
+	}()
+
+	return s, nil
+}
+
+// Endpoint is a connection string of stub
+func (s *Stub) Endpoint() string {
+	return "grpc://" + s.host + ":" + strconv.FormatUint(uint64(s.port), 10) + "/local"
+}
+
+// Stop stops server
+func (s *Stub) Stop() {
+	s.server.Stop()
+}
+
+func (s *Stub) ListEndpoints(context.Context, *Ydb_Discovery.ListEndpointsRequest) (
+	*Ydb_Discovery.ListEndpointsResponse, error,
+) {
+	op, err := operation(&Ydb_Discovery.ListEndpointsResult{
+		Endpoints: []*Ydb_Discovery.EndpointInfo{{
+			Address:  s.host,
+			Port:     s.port,
+			Location: "local",
+			NodeId:   1,
+		}},
+		SelfLocation: "local",
+	})
+
+	return &Ydb_Discovery.ListEndpointsResponse{Operation: op}, err
+}
+
+// operation is a successful operation with result, which may be nil
+func operation(result proto.Message) (*Ydb_Operations.Operation, error) {
+	op := &Ydb_Operations.Operation{
+		Ready:  true,
+		Status: Ydb.StatusIds_SUCCESS,
+	}
+	if result == nil {
+		return op, nil
+	}
+	var err error
+	op.Result, err = anypb.New(result)
+
+	return op, err
+}
+
+func (s *Stub) session() string {
+	return "session-" + strconv.FormatInt(s.sessions.Add(1), 10)
+}
+
+func (s *Stub) tx() string {
+	return "tx-" + strconv.FormatInt(s.txs.Add(1), 10)
+}
+
+// txMeta returns transaction of tx_control which begins new transaction or continues given one
+func (s *Stub) txMeta(control *Ydb_Query.TransactionControl) *Ydb_Query.TransactionMeta {
+	switch {
+	case control.GetBeginTx() != nil:
+		return &Ydb_Query.TransactionMeta{Id: s.tx()}
+	case control.GetTxId() != "":
+		return &Ydb_Query.TransactionMeta{Id: control.GetTxId()}
+	default:
+		return nil
+	}
+}
+
+func (s *Stub) CreateSession(context.Context, *Ydb_Query.CreateSessionRequest) (
+	*Ydb_Query.CreateSessionResponse, error,
+) {
+	return &Ydb_Query.CreateSessionResponse{
+		Status:    Ydb.StatusIds_SUCCESS,
+		SessionId: s.session(),
+		NodeId:    1,
+	}, nil
+}
+
+func (s *Stub) DeleteSession(context.Context, *Ydb_Query.DeleteSessionRequest) (
+	*Ydb_Query.DeleteSessionResponse, error,
+) {
+	return &Ydb_Query.DeleteSessionResponse{
+		Status: Ydb.StatusIds_SUCCESS,
+	}, nil
+}
+
+func (s *Stub) AttachSession(_ *Ydb_Query.AttachSessionRequest, stream Ydb_Query_V1.QueryService_AttachSessionServer,
+) error {
+	if err := stream.Send(&Ydb_Query.SessionState{Status: Ydb.StatusIds_SUCCESS}); err != nil {
+		return err
+	}
+	<-stream.Context().Done()
+
+	return nil
+}
+
+func (s *Stub) BeginTransaction(context.Context, *Ydb_Query.BeginTransactionRequest) (
+	*Ydb_Query.BeginTransactionResponse, error,
+) {
+	return &Ydb_Query.BeginTransactionResponse{
+		Status: Ydb.StatusIds_SUCCESS,
+		TxMeta: &Ydb_Query.TransactionMeta{Id: s.tx()},
+	}, nil
+}
+
+func (s *Stub) CommitTransaction(context.Context, *Ydb_Query.CommitTransactionRequest) (
+	*Ydb_Query.CommitTransactionResponse, error,
+) {
+	return &Ydb_Query.CommitTransactionResponse{Status: Ydb.StatusIds_SUCCESS}, nil
+}
+
+func (s *Stub) RollbackTransaction(context.Context, *Ydb_Query.RollbackTransactionRequest) (
+	*Ydb_Query.RollbackTransactionResponse, error,
+) {
+	return &Ydb_Query.RollbackTransactionResponse{Status: Ydb.StatusIds_SUCCESS}, nil
+}
+
+func (s *Stub) ExecuteQuery(req *Ydb_Query.ExecuteQueryRequest, stream Ydb_Query_V1.QueryService_ExecuteQueryServer,
+) error {
+	return stream.Send(&Ydb_Query.ExecuteQueryResponsePart{
+		Status: Ydb.StatusIds_SUCCESS,
+		TxMeta: s.txMeta(req.GetTxControl()),
+	})
+}
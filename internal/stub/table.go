@@ -0,0 +1,91 @@
+package stub
+
+import (
+	"context"
+
+	"github.com/ydb-platform/ydb-go-genproto/Ydb_Table_V1"
+	"github.com/ydb-platform/ydb-go-genproto/protos/Ydb_Table"
+)
+
+// tableService is a table service of stub: its methods have the same names as methods of query service
+type tableService struct {
+	Ydb_Table_V1.UnimplementedTableServiceServer
+
+	stub *Stub
+}
+
+// txMeta returns transaction of tx_control which begins new transaction or continues given one
+func (t *tableService) txMeta(control *Ydb_Table.TransactionControl) *Ydb_Table.TransactionMeta {
+	switch {
+	case control.GetBeginTx() != nil:
+		return &Ydb_Table.TransactionMeta{Id: t.stub.tx()}
+	case control.GetTxId() != "":
+		return &Ydb_Table.TransactionMeta{Id: control.GetTxId()}
+	default:
+		return nil
+	}
+}
+
+func (t *tableService) CreateSession(context.Context, *Ydb_Table.CreateSessionRequest) (
+	*Ydb_Table.CreateSessionResponse, error,
+) {
+	op, err := operation(&Ydb_Table.CreateSessionResult{SessionId: t.stub.session()})
+
+	return &Ydb_Table.CreateSessionResponse{Operation: op}, err
+}
+
+func (t *tableService) DeleteSession(context.Context, *Ydb_Table.DeleteSessionRequest) (
+	*Ydb_Table.DeleteSessionResponse, error,
+) {
+	op, err := operation(nil)
+
+	return &Ydb_Table.DeleteSessionResponse{Operation: op}, err
+}
+
+func (t *tableService) KeepAlive(context.Context, *Ydb_Table.KeepAliveRequest) (
+	*Ydb_Table.KeepAliveResponse, error,
+) {
+	op, err := operation(&Ydb_Table.KeepAliveResult{SessionStatus: Ydb_Table.KeepAliveResult_SESSION_STATUS_READY})
+
+	return &Ydb_Table.KeepAliveResponse{Operation: op}, err
+}
+
+func (t *tableService) ExecuteDataQuery(_ context.Context, req *Ydb_Table.ExecuteDataQueryRequest) (
+	*Ydb_Table.ExecuteDataQueryResponse, error,
+) {
+	op, err := operation(&Ydb_Table.ExecuteQueryResult{TxMeta: t.txMeta(req.GetTxControl())})
+
+	return &Ydb_Table.ExecuteDataQueryResponse{Operation: op}, err
+}
+
+func (t *tableService) ExecuteSchemeQuery(context.Context, *Ydb_Table.ExecuteSchemeQueryRequest) (
+	*Ydb_Table.ExecuteSchemeQueryResponse, error,
+) {
+	op, err := operation(nil)
+
+	return &Ydb_Table.ExecuteSchemeQueryResponse{Operation: op}, err
+}
+
+func (t *tableService) BeginTransaction(context.Context, *Ydb_Table.BeginTransactionRequest) (
+	*Ydb_Table.BeginTransactionResponse, error,
+) {
+	op, err := operation(&Ydb_Table.BeginTransactionResult{TxMeta: &Ydb_Table.TransactionMeta{Id: t.stub.tx()}})
+
+	return &Ydb_Table.BeginTransactionResponse{Operation: op}, err
+}
+
+func (t *tableService) CommitTransaction(context.Context, *Ydb_Table.CommitTransactionRequest) (
+	*Ydb_Table.CommitTransactionResponse, error,
+) {
+	op, err := operation(&Ydb_Table.CommitTransactionResult{})
+
+	return &Ydb_Table.CommitTransactionResponse{Operation: op}, err
+}
+
+func (t *tableService) RollbackTransaction(context.Context, *Ydb_Table.RollbackTransactionRequest) (
+	*Ydb_Table.RollbackTransactionResponse, error,
+) {
+	op, err := operation(nil)
+
+	return &Ydb_Table.RollbackTransactionResponse{Operation: op}, err
+}
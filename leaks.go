@@ -0,0 +1,146 @@
+package ydb
+
+import (
+	"context"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// callersDepth limits number of frames captured on start of span
+const callersDepth = 32
+
+type (
+	// LeakDetector tracks spans started by adapter which are not ended yet (see WithLeakDetector).
+	// It is a debug tool: program counters of caller are captured on start of every span
+	// and are symbolized only for spans which are reported or returned by OpenSpans.
+	LeakDetector struct {
+		threshold time.Duration
+		report    func(OpenSpan)
+
+		mu   sync.Mutex
+		open map[*span]*openSpan
+	}
+	// OpenSpan is a span which is started but not ended
+	OpenSpan struct {
+		Name  string
+		Start time.Time
+		// Stack is a stack of goroutine which started span
+		Stack string
+	}
+	openSpan struct {
+		name     string
+		start    time.Time
+		pcs      []uintptr
+		reported bool
+	}
+)
+
+// NewLeakDetector makes LeakDetector which reports spans open longer than threshold to report
+// on Check. Report may be nil if only OpenSpans is used.
+func NewLeakDetector(threshold time.Duration, report func(OpenSpan)) *LeakDetector {
+	return &LeakDetector{
+		threshold: threshold,
+		report:    report,
+		open:      make(map[*span]*openSpan),
+	}
+}
+
+func (d *LeakDetector) track(s *span, name string) {
+	var pcs [callersDepth]uintptr
+	// skip runtime.Callers, track and adapter.Start
+	n := runtime.Callers(3, pcs[:])
+	o := &openSpan{
+		name:  name,
+		start: time.Now(),
+		pcs:   append([]uintptr(nil), pcs[:n]...),
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.open[s] = o
+}
+
+// symbolize resolves captured program counters into stack of span
+func (o *openSpan) symbolize() OpenSpan {
+	var b strings.Builder
+	frames := runtime.CallersFrames(o.pcs)
+	for {
+		frame, more := frames.Next()
+		b.WriteString(frame.Function)
+		b.WriteString("\n\t")
+		b.WriteString(frame.File)
+		b.WriteByte(':')
+		b.WriteString(strconv.Itoa(frame.Line))
+		b.WriteByte('\n')
+		if !more {
+			break
+		}
+	}
+
+	return OpenSpan{
+		Name:  o.name,
+		Start: o.start,
+		Stack: b.String(),
+	}
+}
+
+func (d *LeakDetector) untrack(s *span) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	delete(d.open, s)
+}
+
+// OpenSpans returns spans which are started but not ended, in order of start
+func (d *LeakDetector) OpenSpans() []OpenSpan {
+	d.mu.Lock()
+	open := make([]*openSpan, 0, len(d.open))
+	for _, o := range d.open {
+		open = append(open, o)
+	}
+	d.mu.Unlock()
+	sort.Slice(open, func(i, j int) bool {
+		return open[i].start.Before(open[j].start)
+	})
+	spans := make([]OpenSpan, 0, len(open))
+	for _, o := range open {
+		spans = append(spans, o.symbolize())
+	}
+
+	return spans
+}
+
+// Check reports spans which are open longer than threshold. Every span is reported once.
+func (d *LeakDetector) Check() {
+	var leaked []*openSpan
+	d.mu.Lock()
+	for _, o := range d.open {
+		if !o.reported && time.Since(o.start) >= d.threshold {
+			o.reported = true
+			leaked = append(leaked, o)
+		}
+	}
+	d.mu.Unlock()
+	if d.report == nil {
+		return
+	}
+	for _, o := range leaked {
+		d.report(o.symbolize())
+	}
+}
+
+// Run calls Check with interval until ctx is done
+func (d *LeakDetector) Run(ctx context.Context, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			d.Check()
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package ydb
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opentracing/opentracing-go/mocktracer"
+	"github.com/ydb-platform/ydb-go-sdk/v3/spans"
+)
+
+// startLeaked starts span in its own function, so stack of open span is known
+func startLeaked(a spans.Adapter, name string) spans.Span {
+	_, s := a.Start(context.Background(), name)
+
+	return s
+}
+
+func TestLeakDetector(t *testing.T) {
+	const threshold = 50 * time.Millisecond
+	var reported []OpenSpan
+	d := NewLeakDetector(threshold, func(s OpenSpan) {
+		reported = append(reported, s)
+	})
+	a := NewAdapter(WithTracer(mocktracer.New()), WithLeakDetector(d))
+	started := make(map[string]spans.Span)
+	for _, name := range []string{"first", "second", "third", "fourth"} {
+		started[name] = startLeaked(a, name)
+		// start times of spans differ, so order of OpenSpans is defined
+		time.Sleep(time.Millisecond)
+	}
+	started["second"].End()
+	started["fourth"].End()
+
+	open := d.OpenSpans()
+	if len(open) != 2 || open[0].Name != "first" || open[1].Name != "third" {
+		t.Fatalf("OpenSpans() = %v, want first and third", open)
+	}
+	for _, s := range open {
+		// first frame is a caller of adapter Start
+		if !strings.HasPrefix(s.Stack, "github.com/ydb-platform/ydb-go-sdk-opentracing.startLeaked\n") {
+			t.Errorf("stack of %s = %q, want it to start with startLeaked", s.Name, s.Stack)
+		}
+		if !strings.Contains(s.Stack, "leaks_test.go:") || !strings.Contains(s.Stack, ".TestLeakDetector\n") {
+			t.Errorf("stack of %s = %q, want it to contain TestLeakDetector", s.Name, s.Stack)
+		}
+	}
+
+	d.Check()
+	if len(reported) != 0 {
+		t.Fatalf("reported spans before threshold = %v", reported)
+	}
+	time.Sleep(threshold)
+	d.Check()
+	d.Check()
+	if len(reported) != 2 {
+		t.Fatalf("reported spans = %v, want first and third once", reported)
+	}
+	names := map[string]bool{reported[0].Name: true, reported[1].Name: true}
+	if !names["first"] || !names["third"] {
+		t.Errorf("reported spans = %v, want first and third", reported)
+	}
+
+	started["first"].End()
+	started["third"].End()
+	if open = d.OpenSpans(); len(open) != 0 {
+		t.Errorf("OpenSpans() after End = %v, want none", open)
+	}
+}
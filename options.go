@@ -1,6 +1,9 @@
 package ydb
 
 import (
+	"log/slog"
+	"time"
+
 	"github.com/opentracing/opentracing-go"
 	"github.com/ydb-platform/ydb-go-sdk/v3/trace"
 )
@@ -18,3 +21,143 @@ func WithDetailer(d trace.Detailer) Option {
 		c.detailer = d
 	}
 }
+
+// WithTopicSampleRate sets share of topic messages (from 0 to 1) traced by Topics
+func WithTopicSampleRate(rate float64) Option {
+	return func(c *adapter) {
+		c.topicSampleRate = rate
+	}
+}
+
+// WithContentionThreshold sets wait time of semaphore and rate limiter acquire
+// after which acquire span is tagged as contended (100ms by default)
+func WithContentionThreshold(d time.Duration) Option {
+	return func(c *adapter) {
+		c.contentionThreshold = d
+	}
+}
+
+// WithDDLAudit marks spans of schema changing operations with ydb.ddl=true, object path,
+// operation kind and name of calling service
+func WithDDLAudit(service string) Option {
+	return func(c *adapter) {
+		c.ddlAudit = true
+		c.ddlService = service
+	}
+}
+
+// WithDDLSink sets callback which receives audit record for every span of schema changing operation.
+// It is used together with WithDDLAudit.
+func WithDDLSink(sink func(DDLRecord)) Option {
+	return func(c *adapter) {
+		c.ddlSink = sink
+	}
+}
+
+// WithExtractor registers extractor of parent span context which is used when context
+// has no OpenTracing span (see TextMapExtractor). Extractors are used only for parents of
+// started spans: SpanFromContext of adapter returns noop span for context without OpenTracing span
+func WithExtractor(extractor Extractor) Option {
+	return func(c *adapter) {
+		c.extractors = append(c.extractors, extractor)
+	}
+}
+
+// WithImplicitRoot makes span without parent in context an implicit root of its operation: it starts
+// its own trace (with its own sampling decision) tagged with endpoint, database and trace id of driver
+// span (ydb.driver.trace_id), so traces of operations are found by driver without joining
+// the driver span, which lives as long as the process. SpanFromContext of adapter doesn't start
+// implicit root: it returns noop span for context without OpenTracing span
+func WithImplicitRoot() Option {
+	return func(c *adapter) {
+		c.implicitRoot = true
+	}
+}
+
+// WithSlog mirrors events of spans to slog handler with trace and span identifiers: Log as Info,
+// Warn as Warn and Error as Error. Events are mirrored also for spans which are sampled out.
+// If subsystems are given (query, table, conn, retry and so on), only events of their spans are mirrored.
+func WithSlog(handler slog.Handler, subsystems ...string) Option {
+	return func(c *adapter) {
+		c.slog = &slogBridge{
+			handler:    handler,
+			subsystems: make(map[string]bool, len(subsystems)),
+		}
+		for _, s := range subsystems {
+			c.slog.subsystems[s] = true
+		}
+	}
+}
+
+// WithDurationEncoding sets representation of duration fields (milliseconds by default)
+func WithDurationEncoding(e DurationEncoding) Option {
+	return func(c *adapter) {
+		c.encoding.duration = e
+	}
+}
+
+// WithBytesEncoding sets representation of byte slice fields (hex by default)
+func WithBytesEncoding(e BytesEncoding) Option {
+	return func(c *adapter) {
+		c.encoding.bytes = e
+	}
+}
+
+// WithTimeEncoding sets representation of time fields (RFC 3339 by default)
+func WithTimeEncoding(e TimeEncoding) Option {
+	return func(c *adapter) {
+		c.encoding.time = e
+	}
+}
+
+// WithPropagation sets format of span context in carriers of Propagator (native format of tracer by default)
+func WithPropagation(p Propagation) Option {
+	return func(c *adapter) {
+		c.propagation = p
+	}
+}
+
+// WithQueryComments makes WrapConnector append trace and span identifiers of sql.query and sql.exec spans
+// to query text as YQL comment (see Propagator.Comment), so slow queries in YDB are correlated with traces.
+// Comment is unique for every query, so YDB compiles commented query text every time instead of taking
+// it from compiled query cache: enable it for debugging or for workloads which do not rely on the cache.
+// Prepared statements are not commented.
+func WithQueryComments() Option {
+	return func(c *adapter) {
+		c.queryComments = true
+	}
+}
+
+// WithSlowSpanDiagnostics logs runtime stats on spans which are longer than threshold: number of
+// goroutines, heap in use, GC pauses within span and, if stack is true, stack of goroutine which ends span
+func WithSlowSpanDiagnostics(threshold time.Duration, stack bool) Option {
+	return func(c *adapter) {
+		c.diagnostics = &diagnostics{
+			threshold: threshold,
+			stack:     stack,
+		}
+	}
+}
+
+// WithLeakDetector tracks spans started by adapter until they are ended (see LeakDetector)
+func WithLeakDetector(d *LeakDetector) Option {
+	return func(c *adapter) {
+		c.leaks = d
+	}
+}
+
+// WithLateEvents sets callback which receives first call of span method after End for every span.
+// Such calls are ignored by default.
+func WithLateEvents(report func(LateEvent)) Option {
+	return func(c *adapter) {
+		c.lateEvents = report
+	}
+}
+
+// WithClock sets clock of span start and finish timestamps and of measured durations (time.Now by default),
+// so tests and tail sampling get deterministic spans
+func WithClock(now func() time.Time) Option {
+	return func(c *adapter) {
+		c.now = now
+	}
+}
@@ -0,0 +1,82 @@
+package ydb
+
+import (
+	"context"
+
+	"github.com/opentracing/opentracing-go"
+)
+
+type (
+	// Extractor finds span context of other instrumentation (OpenTelemetry, for example) in context.
+	// Extractors are called in order of registration when context has no OpenTracing span.
+	Extractor func(ctx context.Context, tracer opentracing.Tracer) (opentracing.SpanContext, bool)
+	spanKey   struct{}
+)
+
+// TextMapExtractor makes Extractor from func which injects span context of other instrumentation
+// into text map. For OpenTelemetry it is, for example:
+//
+//	func(ctx context.Context, carrier map[string]string) {
+//		otel.GetTextMapPropagator().Inject(ctx, propagation.MapCarrier(carrier))
+//	}
+//
+// Injected context is extracted with tracer, so tracer must understand propagation format.
+func TextMapExtractor(inject func(ctx context.Context, carrier map[string]string)) Extractor {
+	return func(ctx context.Context, tracer opentracing.Tracer) (opentracing.SpanContext, bool) {
+		carrier := opentracing.TextMapCarrier{}
+		inject(ctx, carrier)
+		if len(carrier) == 0 {
+			return nil, false
+		}
+		sc, err := tracer.Extract(opentracing.TextMap, carrier)
+		if err != nil {
+			return nil, false
+		}
+
+		return sc, true
+	}
+}
+
+// parent looks up parent of new span: OpenTracing span from context, then registered extractors
+func (cfg *adapter) parent(ctx context.Context) opentracing.SpanContext {
+	if s := opentracing.SpanFromContext(ctx); s != nil {
+		return s.Context()
+	}
+	for _, extract := range cfg.extractors {
+		if sc, ok := extract(ctx, cfg.tracer); ok && sc != nil {
+			return sc
+		}
+	}
+
+	return nil
+}
+
+// rootTags tags span without parent as implicit root of operation (see WithImplicitRoot)
+func (cfg *adapter) rootTags(tags opentracing.Tags) {
+	if cfg.implicitRoot && cfg.driver != nil {
+		cfg.driver.rootTags(tags)
+	}
+}
+
+// startSpanFromContext is like opentracing.StartSpanFromContextWithTracer, but looks up parent with cfg.parent
+func (cfg *adapter) startSpanFromContext(
+	ctx context.Context, operationName string, opts ...opentracing.StartSpanOption,
+) (opentracing.Span, context.Context) {
+	// start time of clock goes first, so start time passed by caller wins
+	opts = append([]opentracing.StartSpanOption{opentracing.StartTime(cfg.now())}, opts...)
+	if parent := cfg.parent(ctx); parent != nil {
+		opts = append(opts, opentracing.ChildOf(parent))
+	} else if cfg.implicitRoot {
+		tags := opentracing.Tags{}
+		cfg.rootTags(tags)
+		opts = append(opts, tags)
+	}
+	s := cfg.tracer.StartSpan(operationName, opts...)
+
+	return s, opentracing.ContextWithSpan(ctx, s)
+}
+
+// finish finishes span started with startSpanFromContext at time of clock of adapter
+func (cfg *adapter) finish(s opentracing.Span) {
+	s.FinishWithOptions(opentracing.FinishOptions{FinishTime: cfg.now()})
+}
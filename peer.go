@@ -0,0 +1,165 @@
+package ydb
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/ydb-platform/ydb-go-sdk/v3/spans"
+	"github.com/ydb-platform/ydb-go-sdk/v3/trace"
+)
+
+const (
+	sessionIDTag = "ydb.session_id"
+	nodeIDTag    = "ydb.node_id"
+)
+
+type (
+	peerKey  struct{}
+	peerInfo struct {
+		sessionID string
+		nodeID    string
+		address   string
+	}
+	session interface {
+		ID() string
+		NodeID() uint32
+	}
+)
+
+func peerFromContext(ctx context.Context) peerInfo {
+	if p, has := ctx.Value(peerKey{}).(peerInfo); has {
+		return p
+	}
+
+	return peerInfo{}
+}
+
+// merge recognizes session, node and endpoint fields of ydb-go-sdk spans
+func (p peerInfo) merge(fields []spans.KeyValue) (_ peerInfo, changed bool) {
+	for _, field := range fields {
+		var dst *string
+		switch field.Key() {
+		case "session_id", "SessionID":
+			dst = &p.sessionID
+		case "node_id", "nodeID", "NodeID":
+			dst = &p.nodeID
+		case "address":
+			dst = &p.address
+		default:
+			continue
+		}
+		if v := field.String(); v != "" && v != "0" && v != *dst {
+			*dst, changed = v, true
+		}
+	}
+
+	return p, changed
+}
+
+func (p peerInfo) withSession(s session) (_ peerInfo, changed bool) {
+	if s == nil {
+		return p, false
+	}
+	if id := s.ID(); id != "" && id != p.sessionID {
+		p.sessionID, changed = id, true
+	}
+	if nodeID := s.NodeID(); nodeID != 0 {
+		if id := strconv.FormatUint(uint64(nodeID), 10); id != p.nodeID {
+			p.nodeID, changed = id, true
+		}
+	}
+
+	return p, changed
+}
+
+func (p peerInfo) tags(tags opentracing.Tags) {
+	if p.sessionID != "" {
+		tags[sessionIDTag] = p.sessionID
+	}
+	if p.nodeID != "" {
+		tags[nodeIDTag] = p.nodeID
+	}
+	if p.address != "" {
+		tags[string(ext.PeerAddress)] = p.address
+	}
+}
+
+func (p peerInfo) setTags(s opentracing.Span) {
+	tags := opentracing.Tags{}
+	p.tags(tags)
+	for k, v := range tags {
+		s.SetTag(k, v)
+	}
+}
+
+func withPeerFields(ctx context.Context, fields []spans.KeyValue) context.Context {
+	if p, changed := peerFromContext(ctx).merge(fields); changed {
+		return context.WithValue(ctx, peerKey{}, p)
+	}
+
+	return ctx
+}
+
+// withSession puts session of query service into context and tags span made for it by spans package
+func (cfg *adapter) withSession(ctx *context.Context, details trace.Details, s session) {
+	p, changed := peerFromContext(*ctx).withSession(s)
+	if !changed {
+		return
+	}
+	*ctx = context.WithValue(*ctx, peerKey{}, p)
+	if cfg.Details()&details == 0 {
+		return
+	}
+	if span := opentracing.SpanFromContext(*ctx); span != nil {
+		p.setTags(span)
+	}
+}
+
+func peerQuery(cfg *adapter) (t trace.Query) {
+	t.OnSessionExec = func(info trace.QuerySessionExecStartInfo) func(trace.QuerySessionExecDoneInfo) {
+		cfg.withSession(info.Context, trace.QuerySessionEvents, info.Session)
+
+		return nil
+	}
+	t.OnSessionQuery = func(info trace.QuerySessionQueryStartInfo) func(trace.QuerySessionQueryDoneInfo) {
+		cfg.withSession(info.Context, trace.QuerySessionEvents, info.Session)
+
+		return nil
+	}
+	t.OnSessionQueryResultSet = func(
+		info trace.QuerySessionQueryResultSetStartInfo,
+	) func(trace.QuerySessionQueryResultSetDoneInfo) {
+		cfg.withSession(info.Context, trace.QuerySessionEvents, info.Session)
+
+		return nil
+	}
+	t.OnSessionQueryRow = func(info trace.QuerySessionQueryRowStartInfo) func(trace.QuerySessionQueryRowDoneInfo) {
+		cfg.withSession(info.Context, trace.QuerySessionEvents, info.Session)
+
+		return nil
+	}
+	t.OnSessionBegin = func(info trace.QuerySessionBeginStartInfo) func(trace.QuerySessionBeginDoneInfo) {
+		cfg.withSession(info.Context, trace.QuerySessionEvents, info.Session)
+
+		return nil
+	}
+	t.OnSessionDelete = func(info trace.QuerySessionDeleteStartInfo) func(trace.QuerySessionDeleteDoneInfo) {
+		cfg.withSession(info.Context, trace.QuerySessionEvents, info.Session)
+
+		return nil
+	}
+	t.OnTxExec = func(info trace.QueryTxExecStartInfo) func(trace.QueryTxExecDoneInfo) {
+		cfg.withSession(info.Context, trace.QueryTransactionEvents, info.Session)
+
+		return nil
+	}
+	t.OnTxQuery = func(info trace.QueryTxQueryStartInfo) func(trace.QueryTxQueryDoneInfo) {
+		cfg.withSession(info.Context, trace.QueryTransactionEvents, info.Session)
+
+		return nil
+	}
+
+	return t
+}
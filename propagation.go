@@ -0,0 +1,291 @@
+package ydb
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/opentracing/opentracing-go"
+)
+
+// Propagation is a format of span context in text map carriers (HTTP or gRPC headers)
+type Propagation int
+
+const (
+	// PropagationTracer is a native format of tracer (default)
+	PropagationTracer Propagation = iota
+	// PropagationB3Single is a Zipkin B3 format with single b3 header
+	PropagationB3Single
+	// PropagationB3Multi is a Zipkin B3 format with X-B3-TraceId, X-B3-SpanId and X-B3-Sampled headers
+	PropagationB3Multi
+	// PropagationW3C is a W3C Trace Context format with traceparent header
+	PropagationW3C
+	// PropagationJaeger is a Jaeger format with uber-trace-id header
+	PropagationJaeger
+
+	// propagationMock is a format of mocktracer, which is understood only as native format of tracer
+	propagationMock
+)
+
+const (
+	b3SingleHeader     = "b3"
+	b3TraceIDHeader    = "x-b3-traceid"
+	b3SpanIDHeader     = "x-b3-spanid"
+	b3SampledHeader    = "x-b3-sampled"
+	b3FlagsHeader      = "x-b3-flags"
+	w3cHeader          = "traceparent"
+	jaegerHeader       = "uber-trace-id"
+	mockTraceIDHeader  = "mockpfx-ids-traceid"
+	mockSpanIDHeader   = "mockpfx-ids-spanid"
+	mockSampledHeader  = "mockpfx-ids-sampled"
+	traceIDLength      = 32
+	shortTraceIDLength = 16
+	spanIDLength       = 16
+)
+
+// nativeFormats are formats which tracers may inject, in order of lookup
+var nativeFormats = []Propagation{
+	PropagationJaeger, PropagationW3C, PropagationB3Multi, PropagationB3Single, propagationMock,
+}
+
+// traceContext is a tracer independent span context with hex identifiers
+type traceContext struct {
+	traceID string
+	spanID  string
+	sampled bool
+}
+
+// Propagator injects span context of current YDB span into carrier and extracts parent span context
+// from carrier in format set by WithPropagation. Format conversion does not depend on tracer:
+// span context of tracer is converted through its native text map format.
+type Propagator struct {
+	cfg *adapter
+}
+
+// NewPropagator makes Propagator with tracer and format from options
+func NewPropagator(opts ...Option) *Propagator {
+	return &Propagator{cfg: newAdapter(opts...)}
+}
+
+// Inject writes span context of current span from ctx (see WithExtractor) into carrier.
+// opentracing.ErrSpanContextNotFound is returned if there is no span.
+func (p *Propagator) Inject(ctx context.Context, carrier opentracing.TextMapWriter) error {
+	sc := p.cfg.parent(ctx)
+	if sc == nil {
+		return opentracing.ErrSpanContextNotFound
+	}
+
+	return p.InjectContext(sc, carrier)
+}
+
+// InjectContext writes span context into carrier
+func (p *Propagator) InjectContext(sc opentracing.SpanContext, carrier opentracing.TextMapWriter) error {
+	if p.cfg.propagation == PropagationTracer {
+		return p.cfg.tracer.Inject(sc, opentracing.TextMap, carrier)
+	}
+	tc, ok := nativeContext(p.cfg.tracer, sc)
+	if !ok {
+		return opentracing.ErrSpanContextNotFound
+	}
+	tc.write(p.cfg.propagation, carrier)
+
+	return nil
+}
+
+// Extract reads span context from carrier
+func (p *Propagator) Extract(carrier opentracing.TextMapReader) (opentracing.SpanContext, error) {
+	if p.cfg.propagation == PropagationTracer {
+		return p.cfg.tracer.Extract(opentracing.TextMap, carrier)
+	}
+	headers, err := lowerKeys(carrier)
+	if err != nil {
+		return nil, err
+	}
+	tc, ok := parseTraceContext(p.cfg.propagation, headers)
+	if !ok {
+		return nil, opentracing.ErrSpanContextNotFound
+	}
+	native := opentracing.TextMapCarrier{}
+	for _, format := range nativeFormats {
+		tc.write(format, native)
+	}
+
+	return p.cfg.tracer.Extract(opentracing.TextMap, native)
+}
+
+// Extractor makes Extractor for WithExtractor from func which returns incoming headers of ctx
+func (p *Propagator) Extractor(headers func(ctx context.Context) map[string]string) Extractor {
+	return func(ctx context.Context, _ opentracing.Tracer) (opentracing.SpanContext, bool) {
+		h := headers(ctx)
+		if len(h) == 0 {
+			return nil, false
+		}
+		sc, err := p.Extract(opentracing.TextMapCarrier(h))
+		if err != nil {
+			return nil, false
+		}
+
+		return sc, true
+	}
+}
+
+func lowerKeys(carrier opentracing.TextMapReader) (map[string]string, error) {
+	headers := make(map[string]string)
+	err := carrier.ForeachKey(func(key, val string) error {
+		headers[strings.ToLower(key)] = val
+
+		return nil
+	})
+
+	return headers, err
+}
+
+// nativeContext converts span context of tracer by injecting it in native text map format of tracer
+func nativeContext(tracer opentracing.Tracer, sc opentracing.SpanContext) (traceContext, bool) {
+	carrier := opentracing.TextMapCarrier{}
+	if tracer.Inject(sc, opentracing.TextMap, carrier) != nil {
+		return traceContext{}, false
+	}
+	headers, _ := lowerKeys(carrier)
+	for _, format := range nativeFormats {
+		if tc, ok := parseTraceContext(format, headers); ok {
+			return tc, true
+		}
+	}
+
+	return traceContext{}, false
+}
+
+//nolint:funlen
+func parseTraceContext(format Propagation, headers map[string]string) (tc traceContext, ok bool) {
+	switch format {
+	case PropagationJaeger:
+		v, has := headers[jaegerHeader]
+		if !has {
+			return tc, false
+		}
+		if unescaped, err := url.QueryUnescape(v); err == nil {
+			v = unescaped
+		}
+		parts := strings.Split(v, ":")
+		if len(parts) != 4 {
+			return tc, false
+		}
+		flags, err := strconv.ParseUint(parts[3], 16, 8)
+		if err != nil {
+			return tc, false
+		}
+		tc = traceContext{traceID: parts[0], spanID: parts[1], sampled: flags&1 == 1}
+	case PropagationW3C:
+		parts := strings.Split(headers[w3cHeader], "-")
+		if len(parts) != 4 {
+			return tc, false
+		}
+		flags, err := strconv.ParseUint(parts[3], 16, 8)
+		if err != nil {
+			return tc, false
+		}
+		tc = traceContext{traceID: parts[1], spanID: parts[2], sampled: flags&1 == 1}
+	case PropagationB3Multi:
+		tc = traceContext{
+			traceID: headers[b3TraceIDHeader],
+			spanID:  headers[b3SpanIDHeader],
+			sampled: b3Sampled(headers[b3SampledHeader]) || headers[b3FlagsHeader] == "1",
+		}
+	case PropagationB3Single:
+		parts := strings.Split(headers[b3SingleHeader], "-")
+		if len(parts) < 2 {
+			return tc, false
+		}
+		tc = traceContext{traceID: parts[0], spanID: parts[1], sampled: true}
+		if len(parts) > 2 {
+			tc.sampled = b3Sampled(parts[2])
+		}
+	case propagationMock:
+		traceID, err := strconv.ParseUint(headers[mockTraceIDHeader], 10, 64)
+		if err != nil {
+			return tc, false
+		}
+		spanID, err := strconv.ParseUint(headers[mockSpanIDHeader], 10, 64)
+		if err != nil {
+			return tc, false
+		}
+		sampled, _ := strconv.ParseBool(headers[mockSampledHeader])
+		tc = traceContext{
+			traceID: strconv.FormatUint(traceID, 16),
+			spanID:  strconv.FormatUint(spanID, 16),
+			sampled: sampled,
+		}
+	default:
+		return tc, false
+	}
+
+	return tc, validID(tc.traceID, traceIDLength) && validID(tc.spanID, spanIDLength)
+}
+
+// b3Sampled treats deferred sampling decision (empty value) as sampled
+func b3Sampled(v string) bool {
+	return v == "" || v == "1" || v == "d" || v == "true"
+}
+
+func validID(id string, length int) bool {
+	if id == "" || len(id) > length {
+		return false
+	}
+	zero := true
+	for _, c := range id {
+		switch {
+		case c == '0':
+		case '0' <= c && c <= '9', 'a' <= c && c <= 'f', 'A' <= c && c <= 'F':
+			zero = false
+		default:
+			return false
+		}
+	}
+
+	return !zero
+}
+
+func pad(id string, length int) string {
+	if len(id) >= length {
+		return id
+	}
+
+	return strings.Repeat("0", length-len(id)) + id
+}
+
+func (tc traceContext) write(format Propagation, carrier opentracing.TextMapWriter) {
+	flag := "0"
+	if tc.sampled {
+		flag = "1"
+	}
+	b3TraceID := pad(tc.traceID, shortTraceIDLength)
+	if len(tc.traceID) > shortTraceIDLength {
+		b3TraceID = pad(tc.traceID, traceIDLength)
+	}
+	switch format {
+	case PropagationJaeger:
+		carrier.Set(jaegerHeader, tc.traceID+":"+tc.spanID+":0:"+flag)
+	case PropagationW3C:
+		carrier.Set(w3cHeader, "00-"+pad(tc.traceID, traceIDLength)+"-"+pad(tc.spanID, spanIDLength)+"-0"+flag)
+	case PropagationB3Multi:
+		carrier.Set(b3TraceIDHeader, b3TraceID)
+		carrier.Set(b3SpanIDHeader, pad(tc.spanID, spanIDLength))
+		carrier.Set(b3SampledHeader, flag)
+	case PropagationB3Single:
+		carrier.Set(b3SingleHeader, b3TraceID+"-"+pad(tc.spanID, spanIDLength)+"-"+flag)
+	case propagationMock:
+		traceID, err := strconv.ParseInt(tc.traceID, 16, 64)
+		if err != nil {
+			return
+		}
+		spanID, err := strconv.ParseInt(tc.spanID, 16, 64)
+		if err != nil {
+			return
+		}
+		carrier.Set(mockTraceIDHeader, strconv.FormatInt(traceID, 10))
+		carrier.Set(mockSpanIDHeader, strconv.FormatInt(spanID, 10))
+		carrier.Set(mockSampledHeader, strconv.FormatBool(tc.sampled))
+	}
+}
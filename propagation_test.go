@@ -0,0 +1,308 @@
+package ydb
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/mocktracer"
+)
+
+const (
+	testTraceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	testSpanID  = "00f067aa0ba902b7"
+)
+
+func TestTraceContextWrite(t *testing.T) {
+	for _, tt := range []struct {
+		name   string
+		format Propagation
+		tc     traceContext
+		want   opentracing.TextMapCarrier
+	}{
+		{
+			name:   "jaeger",
+			format: PropagationJaeger,
+			tc:     traceContext{traceID: testTraceID, spanID: testSpanID, sampled: true},
+			want:   opentracing.TextMapCarrier{jaegerHeader: testTraceID + ":" + testSpanID + ":0:1"},
+		},
+		{
+			name:   "w3c",
+			format: PropagationW3C,
+			tc:     traceContext{traceID: testTraceID, spanID: testSpanID},
+			want:   opentracing.TextMapCarrier{w3cHeader: "00-" + testTraceID + "-" + testSpanID + "-00"},
+		},
+		{
+			name:   "w3c padding",
+			format: PropagationW3C,
+			tc:     traceContext{traceID: "abc", spanID: "de", sampled: true},
+			want:   opentracing.TextMapCarrier{w3cHeader: "00-00000000000000000000000000000abc-00000000000000de-01"},
+		},
+		{
+			name:   "b3 single short trace id",
+			format: PropagationB3Single,
+			tc:     traceContext{traceID: "abc", spanID: "de", sampled: true},
+			want:   opentracing.TextMapCarrier{b3SingleHeader: "0000000000000abc-00000000000000de-1"},
+		},
+		{
+			name:   "b3 single long trace id",
+			format: PropagationB3Single,
+			tc:     traceContext{traceID: "1" + testSpanID, spanID: testSpanID},
+			want:   opentracing.TextMapCarrier{b3SingleHeader: "0000000000000001" + testSpanID + "-" + testSpanID + "-0"},
+		},
+		{
+			name:   "b3 multi",
+			format: PropagationB3Multi,
+			tc:     traceContext{traceID: "abc", spanID: testSpanID, sampled: true},
+			want: opentracing.TextMapCarrier{
+				b3TraceIDHeader: "0000000000000abc",
+				b3SpanIDHeader:  testSpanID,
+				b3SampledHeader: "1",
+			},
+		},
+		{
+			name:   "mock",
+			format: propagationMock,
+			tc:     traceContext{traceID: "2a", spanID: "2b", sampled: true},
+			want: opentracing.TextMapCarrier{
+				mockTraceIDHeader: "42",
+				mockSpanIDHeader:  "43",
+				mockSampledHeader: "true",
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			carrier := opentracing.TextMapCarrier{}
+			tt.tc.write(tt.format, carrier)
+			if !reflect.DeepEqual(carrier, tt.want) {
+				t.Errorf("write() = %v, want %v", carrier, tt.want)
+			}
+		})
+	}
+}
+
+func TestTraceContextRoundTrip(t *testing.T) {
+	formats := map[string]Propagation{
+		"jaeger":    PropagationJaeger,
+		"w3c":       PropagationW3C,
+		"b3 single": PropagationB3Single,
+		"b3 multi":  PropagationB3Multi,
+	}
+	for _, tc := range []traceContext{
+		{traceID: testTraceID, spanID: testSpanID, sampled: true},
+		{traceID: testTraceID, spanID: testSpanID},
+		{traceID: testSpanID, spanID: "1"},
+		{traceID: "abc", spanID: "de", sampled: true},
+	} {
+		for name, format := range formats {
+			t.Run(name+" "+tc.traceID+" "+tc.spanID, func(t *testing.T) {
+				carrier := opentracing.TextMapCarrier{}
+				tc.write(format, carrier)
+				got, ok := parseTraceContext(format, carrier)
+				if !ok {
+					t.Fatalf("parseTraceContext(%v) is not ok", carrier)
+				}
+				// padding of written identifiers is kept by parse, so identifiers are compared as numbers
+				if !sameID(got.traceID, tc.traceID) || !sameID(got.spanID, tc.spanID) || got.sampled != tc.sampled {
+					t.Errorf("parseTraceContext(%v) = %+v, want %+v", carrier, got, tc)
+				}
+			})
+		}
+	}
+}
+
+func sameID(a, b string) bool {
+	return pad(a, traceIDLength) == pad(b, traceIDLength)
+}
+
+func TestParseTraceContext(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		format  Propagation
+		headers map[string]string
+		want    traceContext
+		ok      bool
+	}{
+		{
+			name:    "jaeger url escaped",
+			format:  PropagationJaeger,
+			headers: map[string]string{jaegerHeader: "abc%3Ade%3A0%3A3"},
+			want:    traceContext{traceID: "abc", spanID: "de", sampled: true},
+			ok:      true,
+		},
+		{
+			name:    "jaeger unsampled",
+			format:  PropagationJaeger,
+			headers: map[string]string{jaegerHeader: "abc:de:0:2"},
+			want:    traceContext{traceID: "abc", spanID: "de"},
+			ok:      true,
+		},
+		{
+			name:    "jaeger missing flags",
+			format:  PropagationJaeger,
+			headers: map[string]string{jaegerHeader: "abc:de:0"},
+		},
+		{
+			name:    "jaeger bad flags",
+			format:  PropagationJaeger,
+			headers: map[string]string{jaegerHeader: "abc:de:0:x"},
+		},
+		{
+			name:    "jaeger zero trace id",
+			format:  PropagationJaeger,
+			headers: map[string]string{jaegerHeader: "0:de:0:1"},
+		},
+		{
+			name:    "w3c",
+			format:  PropagationW3C,
+			headers: map[string]string{w3cHeader: "00-" + testTraceID + "-" + testSpanID + "-01"},
+			want:    traceContext{traceID: testTraceID, spanID: testSpanID, sampled: true},
+			ok:      true,
+		},
+		{
+			name:    "w3c zero trace id",
+			format:  PropagationW3C,
+			headers: map[string]string{w3cHeader: "00-00000000000000000000000000000000-" + testSpanID + "-01"},
+		},
+		{
+			name:    "w3c zero span id",
+			format:  PropagationW3C,
+			headers: map[string]string{w3cHeader: "00-" + testTraceID + "-0000000000000000-01"},
+		},
+		{
+			name:    "w3c too long trace id",
+			format:  PropagationW3C,
+			headers: map[string]string{w3cHeader: "00-0" + testTraceID + "-" + testSpanID + "-01"},
+		},
+		{
+			name:    "w3c not hex",
+			format:  PropagationW3C,
+			headers: map[string]string{w3cHeader: "00-" + testTraceID + "-00f067aa0ba902bz-01"},
+		},
+		{
+			name:    "w3c missing flags",
+			format:  PropagationW3C,
+			headers: map[string]string{w3cHeader: "00-" + testTraceID + "-" + testSpanID},
+		},
+		{
+			name:    "w3c missing header",
+			format:  PropagationW3C,
+			headers: map[string]string{},
+		},
+		{
+			name:    "b3 single deferred sampling",
+			format:  PropagationB3Single,
+			headers: map[string]string{b3SingleHeader: "abc-de"},
+			want:    traceContext{traceID: "abc", spanID: "de", sampled: true},
+			ok:      true,
+		},
+		{
+			name:    "b3 single debug",
+			format:  PropagationB3Single,
+			headers: map[string]string{b3SingleHeader: "abc-de-d-ef"},
+			want:    traceContext{traceID: "abc", spanID: "de", sampled: true},
+			ok:      true,
+		},
+		{
+			name:    "b3 single unsampled",
+			format:  PropagationB3Single,
+			headers: map[string]string{b3SingleHeader: "abc-de-0"},
+			want:    traceContext{traceID: "abc", spanID: "de"},
+			ok:      true,
+		},
+		{
+			name:    "b3 single sampling only",
+			format:  PropagationB3Single,
+			headers: map[string]string{b3SingleHeader: "0"},
+		},
+		{
+			name:   "b3 multi flags",
+			format: PropagationB3Multi,
+			headers: map[string]string{
+				b3TraceIDHeader: "abc",
+				b3SpanIDHeader:  "de",
+				b3SampledHeader: "0",
+				b3FlagsHeader:   "1",
+			},
+			want: traceContext{traceID: "abc", spanID: "de", sampled: true},
+			ok:   true,
+		},
+		{
+			name:    "b3 multi missing span id",
+			format:  PropagationB3Multi,
+			headers: map[string]string{b3TraceIDHeader: "abc"},
+		},
+		{
+			name:    "mock bad trace id",
+			format:  propagationMock,
+			headers: map[string]string{mockTraceIDHeader: "x", mockSpanIDHeader: "1"},
+		},
+		{
+			name:    "tracer format",
+			format:  PropagationTracer,
+			headers: map[string]string{w3cHeader: "00-" + testTraceID + "-" + testSpanID + "-01"},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseTraceContext(tt.format, tt.headers)
+			if ok != tt.ok {
+				t.Fatalf("parseTraceContext(%v) ok = %v, want %v", tt.headers, ok, tt.ok)
+			}
+			if ok && got != tt.want {
+				t.Errorf("parseTraceContext(%v) = %+v, want %+v", tt.headers, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPropagatorRoundTrip(t *testing.T) {
+	for name, format := range map[string]Propagation{
+		"tracer":    PropagationTracer,
+		"jaeger":    PropagationJaeger,
+		"w3c":       PropagationW3C,
+		"b3 single": PropagationB3Single,
+		"b3 multi":  PropagationB3Multi,
+	} {
+		t.Run(name, func(t *testing.T) {
+			tracer := mocktracer.New()
+			p := NewPropagator(WithTracer(tracer), WithPropagation(format))
+			s := tracer.StartSpan("client")
+			defer s.Finish()
+
+			carrier := opentracing.TextMapCarrier{}
+			if err := p.Inject(opentracing.ContextWithSpan(context.Background(), s), carrier); err != nil {
+				t.Fatalf("Inject() error = %v", err)
+			}
+			// header names are case insensitive
+			upper := opentracing.HTTPHeadersCarrier{}
+			_ = carrier.ForeachKey(func(key, val string) error {
+				upper.Set(key, val)
+
+				return nil
+			})
+			sc, err := p.Extract(upper)
+			if err != nil {
+				t.Fatalf("Extract(%v) error = %v", upper, err)
+			}
+			want := s.Context().(mocktracer.MockSpanContext) //nolint:forcetypeassert
+			got, ok := sc.(mocktracer.MockSpanContext)
+			if !ok || got.TraceID != want.TraceID || got.SpanID != want.SpanID || got.Sampled != want.Sampled {
+				t.Errorf("Extract(%v) = %+v, want %+v", upper, sc, want)
+			}
+		})
+	}
+}
+
+func TestPropagatorNotFound(t *testing.T) {
+	p := NewPropagator(WithTracer(mocktracer.New()), WithPropagation(PropagationW3C))
+	err := p.Inject(context.Background(), opentracing.TextMapCarrier{})
+	if !errors.Is(err, opentracing.ErrSpanContextNotFound) {
+		t.Errorf("Inject() error = %v, want %v", err, opentracing.ErrSpanContextNotFound)
+	}
+	_, err = p.Extract(opentracing.TextMapCarrier{w3cHeader: "garbage"})
+	if !errors.Is(err, opentracing.ErrSpanContextNotFound) {
+		t.Errorf("Extract() error = %v, want %v", err, opentracing.ErrSpanContextNotFound)
+	}
+}
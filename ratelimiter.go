@@ -0,0 +1,84 @@
+package ydb
+
+import (
+	"context"
+	"errors"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/ydb-platform/ydb-go-sdk/v3/ratelimiter"
+)
+
+const (
+	ratelimiterAcquireOperationName = "ydb.ratelimiter.acquire"
+
+	ratelimiterNodeTag      = "ydb.ratelimiter.coordination_node"
+	ratelimiterResourceTag  = "ydb.ratelimiter.resource"
+	ratelimiterUnitsTag     = "ydb.ratelimiter.units"
+	ratelimiterThrottledTag = "ydb.ratelimiter.throttled_ms"
+)
+
+type (
+	// ratelimiterClient is generic because option type of AcquireResource is internal in ydb-go-sdk
+	ratelimiterClient[O any] struct {
+		ratelimiter.Client
+
+		acquirer acquirer[O]
+		cfg      *adapter
+	}
+	acquirer[O any] interface {
+		AcquireResource(
+			ctx context.Context,
+			coordinationNodePath string,
+			resourcePath string,
+			amount uint64,
+			opts ...O,
+		) error
+	}
+)
+
+// WrapRatelimiter wraps rate limiter client and starts span around AcquireResource.
+// Span is tagged with resource path, requested units and throttled duration. Acquire which
+// is throttled longer than contention threshold (see WithContentionThreshold) or rejected
+// in report mode is tagged as contended.
+func WrapRatelimiter(c ratelimiter.Client, opts ...Option) ratelimiter.Client {
+	return newRatelimiterClient(c, c, newAdapter(opts...))
+}
+
+func newRatelimiterClient[O any](c ratelimiter.Client, a acquirer[O], cfg *adapter) *ratelimiterClient[O] {
+	return &ratelimiterClient[O]{
+		Client:   c,
+		acquirer: a,
+		cfg:      cfg,
+	}
+}
+
+func (c *ratelimiterClient[O]) AcquireResource(
+	ctx context.Context,
+	coordinationNodePath string,
+	resourcePath string,
+	amount uint64,
+	opts ...O,
+) error {
+	s, ctx := c.cfg.startSpanFromContext(ctx, ratelimiterAcquireOperationName,
+		opentracing.Tags{
+			ratelimiterNodeTag:     coordinationNodePath,
+			ratelimiterResourceTag: resourcePath,
+			ratelimiterUnitsTag:    amount,
+		},
+	)
+	defer c.cfg.finish(s)
+
+	start := c.cfg.now()
+	err := c.acquirer.AcquireResource(ctx, coordinationNodePath, resourcePath, amount, opts...)
+	throttled := c.cfg.now().Sub(start)
+	s.SetTag(ratelimiterThrottledTag, millis(throttled))
+
+	var acquireErr ratelimiter.AcquireError
+	rejected := errors.As(err, &acquireErr)
+	s.SetTag(contendedTag, rejected || throttled >= c.cfg.contentionThreshold)
+	if err != nil {
+		setError(s, err)
+	}
+
+	return err
+}
@@ -0,0 +1,99 @@
+package ydb
+
+import (
+	"context"
+	"sync"
+
+	"github.com/ydb-platform/ydb-go-sdk/v3/spans"
+	"github.com/ydb-platform/ydb-go-sdk/v3/trace"
+)
+
+type (
+	// recorder is a spans.Adapter which keeps names and fields of spans made by ydb-go-sdk,
+	// so benchmarks replay calls with real fields
+	recorder struct {
+		details trace.Details
+		mu      sync.Mutex
+		spans   []*recordedSpan
+	}
+	recordedSpan struct {
+		mu        sync.Mutex
+		name      string
+		fields    []spans.KeyValue
+		logs      [][]spans.KeyValue
+		endFields []spans.KeyValue
+	}
+	recordedSpanKey struct{}
+)
+
+var (
+	_ spans.Adapter = (*recorder)(nil)
+	_ spans.Span    = (*recordedSpan)(nil)
+)
+
+func (r *recorder) Details() trace.Details {
+	return r.details
+}
+
+func (r *recorder) SpanFromContext(ctx context.Context) spans.Span {
+	if s, has := ctx.Value(recordedSpanKey{}).(*recordedSpan); has {
+		return s
+	}
+
+	return &recordedSpan{}
+}
+
+func (r *recorder) Start(ctx context.Context, operationName string, fields ...spans.KeyValue) (
+	context.Context, spans.Span,
+) {
+	s := &recordedSpan{
+		name:   operationName,
+		fields: fields,
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.spans = append(r.spans, s)
+
+	return context.WithValue(ctx, recordedSpanKey{}, s), s
+}
+
+func (r *recorder) recorded() []*recordedSpan {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]*recordedSpan(nil), r.spans...)
+}
+
+func (s *recordedSpan) ID() (string, bool) {
+	return "", false
+}
+
+func (s *recordedSpan) TraceID() (string, bool) {
+	return "", false
+}
+
+func (s *recordedSpan) Link(spans.Span, ...spans.KeyValue) {}
+
+func (s *recordedSpan) Log(_ string, fields ...spans.KeyValue) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.logs = append(s.logs, fields)
+}
+
+func (s *recordedSpan) Warn(_ error, fields ...spans.KeyValue) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.logs = append(s.logs, fields)
+}
+
+func (s *recordedSpan) Error(_ error, fields ...spans.KeyValue) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.logs = append(s.logs, fields)
+}
+
+func (s *recordedSpan) End(fields ...spans.KeyValue) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.endFields = fields
+}
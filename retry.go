@@ -0,0 +1,402 @@
+package ydb
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/opentracing/opentracing-go/log"
+	"github.com/ydb-platform/ydb-go-sdk/v3/retry"
+	"github.com/ydb-platform/ydb-go-sdk/v3/trace"
+)
+
+const (
+	retryAttemptOperationName = "ydb.retry.attempt"
+
+	retryAttemptTag      = "ydb.retry.attempt"
+	retryAttemptsTag     = "ydb.retry.attempts"
+	retryIdempotentTag   = "ydb.retry.idempotent"
+	retryBackoffTag      = "ydb.retry.backoff_ms"
+	retryBackoffTotalTag = "ydb.retry.backoff_total_ms"
+	retryFastBackoffsTag = "ydb.retry.fast_backoffs"
+	retrySlowBackoffsTag = "ydb.retry.slow_backoffs"
+)
+
+type (
+	retryLoopKey    struct{}
+	retryAttemptKey struct{}
+	retryLoop       struct {
+		cfg *adapter
+		// idempotent is nil if retry loop does not report idempotency (query service)
+		idempotent *bool
+		attempts   atomic.Int32
+
+		// claimable is set by table and query clients, which make an internal
+		// retry.Retry loop for the same operation
+		claimable atomic.Bool
+
+		mu  sync.Mutex
+		txs []*txSpan
+		// open is attempt started by transaction (see openAttempt)
+		open         *attemptSpan
+		lastEnd      time.Time
+		backoff      time.Duration
+		fastBackoffs int
+		slowBackoffs int
+	}
+	attemptSpan struct {
+		loop      *retryLoop
+		span      opentracing.Span
+		number    int
+		txsBefore int
+		err       atomic.Pointer[error]
+		// adopted is set when operation wrapped with Attempts runs in attempt opened by transaction
+		adopted atomic.Bool
+		once    sync.Once
+	}
+)
+
+func withRetryLoop(ctx context.Context, loop *retryLoop) context.Context {
+	return context.WithValue(ctx, retryLoopKey{}, loop)
+}
+
+func retryLoopFromContext(ctx context.Context) *retryLoop {
+	if loop, has := ctx.Value(retryLoopKey{}).(*retryLoop); has {
+		return loop
+	}
+
+	return nil
+}
+
+func retryAttempt(ctx context.Context) int {
+	if attempt, has := ctx.Value(retryAttemptKey{}).(int); has {
+		return attempt
+	}
+
+	return 0
+}
+
+func millis(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// Attempts wraps retry operation (for retry.Do, retry.DoTx, table.Client.Do and so on)
+// and makes ydb.retry.attempt span for each call of operation. Attempt span is tagged with
+// attempt number, idempotency (if retry loop reports it), backoff before attempt, and retryability
+// of the attempt error.
+// Attempts are counted by retry loop traced with WithTraces, call outside of it is a single
+// attempt traced with opts.
+func Attempts[T any](op func(context.Context, T) error, opts ...Option) func(context.Context, T) error {
+	cfg := newAdapter(opts...)
+
+	return func(ctx context.Context, v T) error {
+		loop := retryLoopFromContext(ctx)
+		if loop == nil {
+			loop = &retryLoop{cfg: cfg}
+		}
+
+		return loop.attempt(ctx, func(ctx context.Context) error {
+			return op(ctx, v)
+		})
+	}
+}
+
+// backoffName maps backoff type of retry mode to fast, slow or none.
+// Backoff type itself is internal in ydb-go-sdk, so only its String is available.
+func backoffName(m interface{ MustBackoff() bool }, backoffType fmt.Stringer) string {
+	if !m.MustBackoff() {
+		return "none"
+	}
+	switch backoffType.String() {
+	case "fast backoff":
+		return "fast"
+	case "slow backoff":
+		return "slow"
+	default:
+		return backoffType.String()
+	}
+}
+
+// startAttempt starts span of the next attempt of retry loop
+func (loop *retryLoop) startAttempt(ctx context.Context) (context.Context, *attemptSpan) {
+	loop.claimable.Store(false)
+	a := &attemptSpan{loop: loop, number: int(loop.attempts.Add(1))}
+	tags := opentracing.Tags{
+		retryAttemptTag: a.number,
+	}
+	if loop.idempotent != nil {
+		tags[retryIdempotentTag] = *loop.idempotent
+	}
+	loop.mu.Lock()
+	if !loop.lastEnd.IsZero() {
+		backoff := loop.cfg.now().Sub(loop.lastEnd)
+		loop.backoff += backoff
+		tags[retryBackoffTag] = millis(backoff)
+	}
+	a.txsBefore = len(loop.txs)
+	loop.mu.Unlock()
+
+	a.span, ctx = loop.cfg.startSpanFromContext(ctx, retryAttemptOperationName, tags)
+
+	return context.WithValue(ctx, retryAttemptKey{}, a.number), a
+}
+
+// openAttempt starts attempt of transaction which is begun by retry loop before its operation is called,
+// so attempt span covers begin, statements and commit. Attempt ends with transaction, operation
+// wrapped with Attempts runs in it.
+func (loop *retryLoop) openAttempt(ctx context.Context) (context.Context, *attemptSpan) {
+	loop.mu.Lock()
+	prev := loop.open
+	loop.mu.Unlock()
+	if prev != nil {
+		prev.end(nil)
+	}
+	ctx, a := loop.startAttempt(ctx)
+	loop.mu.Lock()
+	loop.open = a
+	loop.mu.Unlock()
+
+	return ctx, a
+}
+
+func (loop *retryLoop) attempt(ctx context.Context, op func(ctx context.Context) error) error {
+	loop.mu.Lock()
+	a := loop.open
+	loop.mu.Unlock()
+	if a != nil && a.adopted.CompareAndSwap(false, true) {
+		err := op(context.WithValue(opentracing.ContextWithSpan(ctx, a.span), retryAttemptKey{}, a.number))
+		a.fail(err)
+
+		return err
+	}
+	ctx, a = loop.startAttempt(ctx)
+	err := op(ctx)
+	a.end(err)
+
+	return err
+}
+
+// endTry ends transactions of open attempt after try of query service pool: query service has no
+// commit event, so transaction is committed if try has no error
+func (loop *retryLoop) endTry(err error) {
+	loop.mu.Lock()
+	a := loop.open
+	var txs []*txSpan
+	if a != nil && a.txsBefore <= len(loop.txs) {
+		txs = append(txs, loop.txs[a.txsBefore:]...)
+	}
+	loop.mu.Unlock()
+	for _, tx := range txs {
+		if err == nil {
+			tx.finish(txOutcomeCommit, nil)
+		} else {
+			tx.finish(txOutcomeRollback, err)
+		}
+	}
+	if a != nil {
+		a.end(err)
+	}
+}
+
+func (a *attemptSpan) fail(err error) {
+	if err != nil {
+		a.err.Store(&err)
+	}
+}
+
+// end ends attempt with error of its operation, or with err if operation has no error
+func (a *attemptSpan) end(err error) {
+	a.once.Do(func() {
+		if opErr := a.err.Load(); opErr != nil {
+			err = *opErr
+		}
+		loop := a.loop
+		var backoff string
+		if err != nil {
+			m := retry.Check(err)
+			backoff = backoffName(m, m.BackoffType())
+			ext.Error.Set(a.span, true)
+			errorTags(a.span, err)
+			fields := []log.Field{log.Error(err), log.String("backoff", backoff)}
+			if loop.idempotent != nil {
+				fields = append(fields, log.Bool("retryable", m.MustRetry(*loop.idempotent)))
+			}
+			a.span.LogFields(fields...)
+		}
+		loop.cfg.finish(a.span)
+
+		loop.mu.Lock()
+		defer loop.mu.Unlock()
+		if loop.open == a {
+			loop.open = nil
+		}
+		// error of attempt is error of its transactions, query service has no commit event
+		if a.txsBefore <= len(loop.txs) {
+			for _, tx := range loop.txs[a.txsBefore:] {
+				tx.fail(err)
+			}
+		}
+		loop.lastEnd = loop.cfg.now()
+		switch backoff {
+		case "fast":
+			loop.fastBackoffs++
+		case "slow":
+			loop.slowBackoffs++
+		}
+	})
+}
+
+func (loop *retryLoop) addTx(tx *txSpan) {
+	loop.mu.Lock()
+	defer loop.mu.Unlock()
+	loop.txs = append(loop.txs, tx)
+}
+
+// finishTxs ends transactions which have no explicit commit or rollback events:
+// transactions of query service and transactions committed with the last statement.
+// Only the last transaction may be committed, others are rolled back with error of their attempt.
+func (loop *retryLoop) finishTxs(err error) {
+	loop.mu.Lock()
+	txs, open := loop.txs, loop.open
+	loop.txs = nil
+	loop.mu.Unlock()
+	for i, tx := range txs {
+		switch {
+		case i < len(txs)-1:
+			tx.finish(txOutcomeRollback, tx.lastErr())
+		case err == nil:
+			tx.finish(txOutcomeCommit, nil)
+		default:
+			tx.finish(txOutcomeRollback, err)
+		}
+	}
+	if open != nil {
+		open.end(err)
+	}
+}
+
+func (loop *retryLoop) finish(s opentracing.Span, attempts int) {
+	loop.mu.Lock()
+	defer loop.mu.Unlock()
+	s.SetTag(retryAttemptsTag, attempts)
+	if loop.idempotent != nil {
+		s.SetTag(retryIdempotentTag, *loop.idempotent)
+	}
+	if loop.attempts.Load() == 0 {
+		return
+	}
+	s.SetTag(retryBackoffTotalTag, millis(loop.backoff))
+	s.SetTag(retryFastBackoffsTag, loop.fastBackoffs)
+	s.SetTag(retrySlowBackoffsTag, loop.slowBackoffs)
+}
+
+// startRetryLoop puts retry loop into context and returns finish func which
+// tags the operation span started by spans package with retry summary
+func startRetryLoop(cfg *adapter, ctx *context.Context, idempotent *bool, claimable bool) func(
+	attempts int, err error,
+) {
+	if loop := retryLoopFromContext(*ctx); loop != nil && loop.claimable.CompareAndSwap(true, false) {
+		// table.WithIdempotent is an option of internal retry loop, so table client reports
+		// idempotency of operation only to the internal loop
+		if idempotent != nil {
+			loop.idempotent = idempotent
+		}
+
+		return nil
+	}
+	loop := &retryLoop{
+		cfg:        cfg,
+		idempotent: idempotent,
+	}
+	loop.claimable.Store(claimable)
+	parent := opentracing.SpanFromContext(*ctx)
+	*ctx = withRetryLoop(*ctx, loop)
+
+	return func(attempts int, err error) {
+		loop.finishTxs(err)
+		if s := opentracing.SpanFromContext(*ctx); s != nil && s != parent {
+			loop.finish(s, attempts)
+		}
+	}
+}
+
+func retryTrace(cfg *adapter) (t trace.Retry) {
+	t.OnRetry = func(info trace.RetryLoopStartInfo) func(trace.RetryLoopDoneInfo) {
+		finish := startRetryLoop(cfg, info.Context, &info.Idempotent, false)
+		if finish == nil {
+			return nil
+		}
+
+		return func(info trace.RetryLoopDoneInfo) {
+			finish(info.Attempts, info.Error)
+		}
+	}
+
+	return t
+}
+
+func retryTable(cfg *adapter) (t trace.Table) {
+	t.OnDo = func(info trace.TableDoStartInfo) func(trace.TableDoDoneInfo) {
+		finish := startRetryLoop(cfg, info.Context, &info.Idempotent, true)
+		if finish == nil {
+			return nil
+		}
+
+		return func(info trace.TableDoDoneInfo) {
+			finish(info.Attempts, info.Error)
+		}
+	}
+	t.OnDoTx = func(info trace.TableDoTxStartInfo) func(trace.TableDoTxDoneInfo) {
+		finish := startRetryLoop(cfg, info.Context, &info.Idempotent, true)
+		if finish == nil {
+			return nil
+		}
+
+		return func(info trace.TableDoTxDoneInfo) {
+			finish(info.Attempts, info.Error)
+		}
+	}
+
+	return t
+}
+
+// retryQuery traces retry loops of query service, which does not report idempotency of operation,
+// so its loops and attempts are not tagged with ydb.retry.idempotent
+func retryQuery(cfg *adapter) (t trace.Query) {
+	t.OnDo = func(info trace.QueryDoStartInfo) func(trace.QueryDoDoneInfo) {
+		finish := startRetryLoop(cfg, info.Context, nil, false)
+		if finish == nil {
+			return nil
+		}
+
+		return func(info trace.QueryDoDoneInfo) {
+			finish(info.Attempts, info.Error)
+		}
+	}
+	t.OnDoTx = func(info trace.QueryDoTxStartInfo) func(trace.QueryDoTxDoneInfo) {
+		finish := startRetryLoop(cfg, info.Context, nil, false)
+		if finish == nil {
+			return nil
+		}
+
+		return func(info trace.QueryDoTxDoneInfo) {
+			finish(info.Attempts, info.Error)
+		}
+	}
+	t.OnPoolTry = func(info trace.QueryPoolTryStartInfo) func(trace.QueryPoolTryDoneInfo) {
+		loop := retryLoopFromContext(*info.Context)
+		if loop == nil {
+			return nil
+		}
+
+		return func(info trace.QueryPoolTryDoneInfo) {
+			loop.endTry(info.Error)
+		}
+	}
+
+	return t
+}
@@ -0,0 +1,152 @@
+package ydb
+
+import (
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/opentracing/opentracing-go/log"
+)
+
+var (
+	_ opentracing.Tracer = (*rulesTracer)(nil)
+	_ opentracing.Span   = (*rulesSpan)(nil)
+)
+
+// queryKeys are keys of query text in spans of ydb-go-sdk, they follow redaction of db.statement
+var queryKeys = []string{"query", "Query"}
+
+type (
+	// rulesTracer applies attribute rules (see Config) to all tags and log fields of spans,
+	// including tags set by this package directly and fields of ydb-go-sdk spans
+	rulesTracer struct {
+		opentracing.Tracer
+
+		encoding *encoding
+	}
+	rulesSpan struct {
+		opentracing.Span
+
+		tracer *rulesTracer
+	}
+)
+
+// withRules adds rules for query text keys of ydb-go-sdk spans which follow rule of db.statement
+func withRules(rules map[string]AttributeRule) map[string]AttributeRule {
+	r, has := rules[string(ext.DBStatement)]
+	if !has || r.Action == AttributeRename {
+		return rules
+	}
+	for _, key := range queryKeys {
+		if _, has := rules[key]; !has {
+			rules[key] = AttributeRule{Key: key, Action: r.Action}
+		}
+	}
+
+	return rules
+}
+
+// statement applies rule of db.statement to query text which is not a tag, such as DDLRecord.Statement
+func (e *encoding) statement(query string) string {
+	_, v, ok := e.apply(string(ext.DBStatement), query)
+	if !ok {
+		return ""
+	}
+
+	return v.(string) //nolint:forcetypeassert
+}
+
+func (e *encoding) applyField(f log.Field) (log.Field, bool) {
+	r, has := e.rules[f.Key()]
+	if !has {
+		return f, true
+	}
+	switch r.Action {
+	case AttributeDrop:
+		return f, false
+	case AttributeRedact:
+		return log.String(f.Key(), redacted), true
+	default:
+		return e.attribute(r.To, f.Value()), true
+	}
+}
+
+func (e *encoding) applyFields(fields []log.Field) []log.Field {
+	applied := make([]log.Field, 0, len(fields))
+	for _, f := range fields {
+		if f, ok := e.applyField(f); ok {
+			applied = append(applied, f)
+		}
+	}
+
+	return applied
+}
+
+func (t *rulesTracer) StartSpan(operationName string, opts ...opentracing.StartSpanOption) opentracing.Span {
+	var sso opentracing.StartSpanOptions
+	for _, opt := range opts {
+		opt.Apply(&sso)
+	}
+	tags := make(opentracing.Tags, len(sso.Tags))
+	for k, v := range sso.Tags {
+		if k, v, ok := t.encoding.apply(k, v); ok {
+			tags[k] = v
+		}
+	}
+	applied := make([]opentracing.StartSpanOption, 0, len(sso.References)+2)
+	for _, ref := range sso.References {
+		applied = append(applied, ref)
+	}
+	applied = append(applied, tags)
+	if !sso.StartTime.IsZero() {
+		applied = append(applied, opentracing.StartTime(sso.StartTime))
+	}
+
+	return &rulesSpan{
+		Span:   t.Tracer.StartSpan(operationName, applied...),
+		tracer: t,
+	}
+}
+
+func (s *rulesSpan) FinishWithOptions(opts opentracing.FinishOptions) {
+	for i := range opts.LogRecords {
+		opts.LogRecords[i].Fields = s.tracer.encoding.applyFields(opts.LogRecords[i].Fields)
+	}
+	s.Span.FinishWithOptions(opts)
+}
+
+func (s *rulesSpan) SetOperationName(operationName string) opentracing.Span {
+	s.Span.SetOperationName(operationName)
+
+	return s
+}
+
+func (s *rulesSpan) SetTag(key string, value interface{}) opentracing.Span {
+	if key, value, ok := s.tracer.encoding.apply(key, value); ok {
+		s.Span.SetTag(key, value)
+	}
+
+	return s
+}
+
+func (s *rulesSpan) LogFields(fields ...log.Field) {
+	s.Span.LogFields(s.tracer.encoding.applyFields(fields)...)
+}
+
+func (s *rulesSpan) LogKV(alternatingKeyValues ...interface{}) {
+	fields, err := log.InterleavedKVToFields(alternatingKeyValues...)
+	if err != nil {
+		s.Span.LogFields(log.Error(err), log.String("function", "LogKV"))
+
+		return
+	}
+	s.LogFields(fields...)
+}
+
+func (s *rulesSpan) SetBaggageItem(restrictedKey, value string) opentracing.Span {
+	s.Span.SetBaggageItem(restrictedKey, value)
+
+	return s
+}
+
+func (s *rulesSpan) Tracer() opentracing.Tracer {
+	return s.tracer
+}
@@ -0,0 +1,71 @@
+package ydb
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/ydb-platform/ydb-go-sdk/v3/spans"
+)
+
+const (
+	slogTraceIDKey   = "trace_id"
+	slogSpanIDKey    = "span_id"
+	slogSubsystemKey = "subsystem"
+)
+
+// slogBridge mirrors span events to slog handler, also for spans which are sampled out
+type slogBridge struct {
+	handler    slog.Handler
+	subsystems map[string]bool
+	tracer     opentracing.Tracer
+}
+
+// enabled reports whether events of spans of given subsystem are mirrored
+func (b *slogBridge) enabled(subsystem string) bool {
+	return len(b.subsystems) == 0 || b.subsystems[subsystem]
+}
+
+func (b *slogBridge) emit(s *span, level slog.Level, msg string, err error, fields []spans.KeyValue) {
+	ctx := context.Background()
+	if !b.handler.Enabled(ctx, level) {
+		return
+	}
+	r := slog.NewRecord(s.cfg.now(), level, msg, 0)
+	if tc, ok := nativeContext(b.tracer, s.span.Context()); ok {
+		r.AddAttrs(slog.String(slogTraceIDKey, tc.traceID), slog.String(slogSpanIDKey, tc.spanID))
+	}
+	if s.subsystem != "" {
+		r.AddAttrs(slog.String(slogSubsystemKey, s.subsystem))
+	}
+	if err != nil {
+		r.AddAttrs(slog.Any("error", err))
+	}
+	for _, field := range fields {
+		if key, v, ok := s.cfg.encoding.apply(field.Key(), s.cfg.encoding.fieldValue(field)); ok {
+			// record is emitted, so Stringer is converted now: JSON handler does not call String
+			if st, ok := v.(fmt.Stringer); ok {
+				v = st.String()
+			}
+			r.AddAttrs(slog.Any(key, v))
+		}
+	}
+	_ = b.handler.Handle(ctx, r)
+}
+
+// subsystem of span is a package of ydb-go-sdk which makes it (query, table, conn, retry and so on),
+// or second part of name of span made by this package (ydb.conn, ydb.coordination.semaphore.acquire)
+func subsystem(operationName string) string {
+	name := strings.TrimPrefix(operationName, "github.com/ydb-platform/ydb-go-sdk/v3/")
+	name = strings.TrimPrefix(name, "internal/")
+	if rest, own := strings.CutPrefix(name, "ydb."); own && !strings.HasPrefix(rest, "(") {
+		name = rest
+	}
+	if i := strings.IndexAny(name, "./"); i >= 0 {
+		return name[:i]
+	}
+
+	return name
+}
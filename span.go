@@ -1,7 +1,14 @@
 package ydb
 
 import (
+	"context"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
 	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
 	"github.com/opentracing/opentracing-go/log"
 	"github.com/ydb-platform/ydb-go-sdk/v3/spans"
 )
@@ -14,8 +21,38 @@ var (
 type (
 	span struct {
 		span opentracing.Span
+		cfg  *adapter
+		name string
+
+		// sampled is false for spans which are not recorded by tracer,
+		// fields of such spans are not converted
+		sampled bool
+
+		// ctx is a context of call traced by span, its error is checked on End and Error
+		ctx context.Context //nolint:containedctx
+
+		// start is set when runtime stats are captured on slow spans (see WithSlowSpanDiagnostics)
+		start time.Time
+
+		// slog is set when events of span are mirrored to slog handler (see WithSlog)
+		slog      *slogBridge
+		subsystem string
+
+		// mu makes End exclusive with events: events of streams come from other goroutines,
+		// and tracers must not get events of finished span
+		mu           sync.RWMutex
+		finished     bool
+		lateReported atomic.Bool
 	}
 	noopSpan struct{}
+
+	// LateEvent is a call of span method after End (see WithLateEvents)
+	LateEvent struct {
+		// Name is an operation name of span
+		Name string
+		// Method is a called method: Log, Warn, Error or End
+		Method string
+	}
 )
 
 func (noopSpan) ID() (_ string, valid bool) {
@@ -41,24 +78,65 @@ func (s *span) ID() (_ string, valid bool) {
 }
 
 func (s *span) Log(msg string, fields ...spans.KeyValue) {
-	s.span.LogFields(append(
-		fieldsToFields(fields),
-		log.Event(msg),
-	)...)
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.finished {
+		s.late("Log")
+
+		return
+	}
+	if s.slog != nil {
+		s.slog.emit(s, slog.LevelInfo, msg, nil, fields)
+	}
+	if !s.sampled {
+		return
+	}
+	s.span.LogFields(s.cfg.encoding.fieldsToFields(fields, log.Event(msg))...)
 }
 
 func (s *span) Warn(err error, fields ...spans.KeyValue) {
-	s.span.LogFields(append(
-		fieldsToFields(fields),
-		log.Event(err.Error()),
-	)...)
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.finished {
+		s.late("Warn")
+
+		return
+	}
+	if s.slog != nil {
+		s.slog.emit(s, slog.LevelWarn, err.Error(), err, fields)
+	}
+	if !s.sampled {
+		return
+	}
+	s.span.LogFields(s.cfg.encoding.fieldsToFields(fields, log.Event(err.Error()))...)
 }
 
 func (s *span) Error(err error, fields ...spans.KeyValue) {
-	s.span.LogFields(append(
-		fieldsToFields(fields),
-		log.Error(err),
-	)...)
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.finished {
+		s.late("Error")
+
+		return
+	}
+	if s.slog != nil {
+		s.slog.emit(s, slog.LevelError, err.Error(), err, fields)
+	}
+	if !s.sampled {
+		return
+	}
+	ext.Error.Set(s.span, true)
+	errorTags(s.span, err)
+	contextTags(s.ctx, s.span)
+	s.span.LogFields(s.cfg.encoding.fieldsToFields(fields, log.Error(err))...)
+}
+
+// late reports first call of span method after End
+func (s *span) late(method string) {
+	if s.cfg.lateEvents == nil || !s.lateReported.CompareAndSwap(false, true) {
+		return
+	}
+	s.cfg.lateEvents(LateEvent{Name: s.name, Method: method})
 }
 
 func (s *span) TraceID() (string, bool) {
@@ -66,13 +144,47 @@ func (s *span) TraceID() (string, bool) {
 }
 
 func (s *span) Link(link spans.Span, fields ...spans.KeyValue) {
-	_ = opentracing.FollowsFrom(link.(*span).span.Context()) //nolint:forcetypeassert
+	if l, ok := link.(*span); ok {
+		_ = opentracing.FollowsFrom(l.span.Context())
+	}
 }
 
 func (s *span) End(fields ...spans.KeyValue) {
+	s.mu.Lock()
+	finished := s.finished
+	s.finished = true
+	s.mu.Unlock()
+	if finished {
+		s.late("End")
+
+		return
+	}
+	if s.cfg.leaks != nil {
+		s.cfg.leaks.untrack(s)
+	}
+	if !s.sampled {
+		s.span.Finish()
+
+		return
+	}
+	finishTime := s.cfg.now()
+	contextTags(s.ctx, s.span)
+	if s.cfg.diagnostics != nil {
+		s.cfg.diagnostics.capture(s.span, s.start)
+	}
+	if len(fields) == 0 {
+		s.span.FinishWithOptions(opentracing.FinishOptions{FinishTime: finishTime})
+
+		return
+	}
+	if p, changed := (peerInfo{}).merge(fields); changed {
+		p.setTags(s.span)
+	}
 	s.span.FinishWithOptions(opentracing.FinishOptions{
+		FinishTime: finishTime,
 		LogRecords: []opentracing.LogRecord{{
-			Fields: fieldsToFields(fields),
+			Timestamp: finishTime,
+			Fields:    s.cfg.encoding.fieldsToFields(fields),
 		}},
 	})
 }
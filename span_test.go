@@ -0,0 +1,108 @@
+package ydb
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/opentracing/opentracing-go/mocktracer"
+)
+
+func TestSpanEndOnce(t *testing.T) {
+	tracer := mocktracer.New()
+	var late []LateEvent
+	cfg := newAdapter(WithTracer(tracer), WithLateEvents(func(e LateEvent) {
+		late = append(late, e)
+	}))
+	_, s := cfg.Start(context.Background(), "span")
+	s.Log("before end")
+	s.End()
+	s.End()
+	s.Log("log")
+	s.Warn(errors.New("warn"))
+	s.Error(errors.New("error"))
+
+	finished := tracer.FinishedSpans()
+	if len(finished) != 1 {
+		t.Fatalf("finished spans = %d, want 1", len(finished))
+	}
+	if logs := len(finished[0].Logs()); logs != 1 {
+		t.Errorf("log records = %d, want only record before End", logs)
+	}
+	if _, has := finished[0].Tags()["error"]; has {
+		t.Error("Error after End tagged span with error")
+	}
+	// only first late call of span is reported
+	if want := []LateEvent{{Name: "span", Method: "End"}}; !reflect.DeepEqual(late, want) {
+		t.Errorf("late events = %v, want %v", late, want)
+	}
+}
+
+func TestSpanLateEventsPerSpan(t *testing.T) {
+	for _, method := range []string{"Log", "Warn", "Error", "End"} {
+		t.Run(method, func(t *testing.T) {
+			var late []LateEvent
+			cfg := newAdapter(WithTracer(mocktracer.New()), WithLateEvents(func(e LateEvent) {
+				late = append(late, e)
+			}))
+			var want []LateEvent
+			for _, name := range []string{"first", "second"} {
+				_, s := cfg.Start(context.Background(), name)
+				s.End()
+				for i := 0; i < 3; i++ {
+					switch method {
+					case "Log":
+						s.Log("late")
+					case "Warn":
+						s.Warn(errors.New("late"))
+					case "Error":
+						s.Error(errors.New("late"))
+					default:
+						s.End()
+					}
+				}
+				want = append(want, LateEvent{Name: name, Method: method})
+			}
+			if !reflect.DeepEqual(late, want) {
+				t.Errorf("late events = %v, want %v", late, want)
+			}
+		})
+	}
+}
+
+func TestSpanConcurrentLogAndEnd(t *testing.T) {
+	tracer := mocktracer.New()
+	cfg := newAdapter(WithTracer(tracer))
+	for i := 0; i < 100; i++ {
+		tracer.Reset()
+		_, s := cfg.Start(context.Background(), "stream")
+		var (
+			wg    sync.WaitGroup
+			start = make(chan struct{})
+		)
+		for j := 0; j < 4; j++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				<-start
+				for k := 0; k < 10; k++ {
+					s.Log("event")
+					s.Error(errors.New("event"))
+				}
+			}()
+		}
+		close(start)
+		s.End()
+		finished := tracer.FinishedSpans()
+		if len(finished) != 1 {
+			t.Fatalf("finished spans = %d, want 1", len(finished))
+		}
+		logs := len(finished[0].Logs())
+		wg.Wait()
+		if after := len(finished[0].Logs()); after != logs {
+			t.Fatalf("log records after End = %d, want %d", after, logs)
+		}
+	}
+}
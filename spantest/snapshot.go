@@ -0,0 +1,148 @@
+package spantest
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Normalizer replaces value of tag or log field which differs from run to run
+type Normalizer func(key string, value interface{}) interface{}
+
+var (
+	// ErrSnapshotMismatch is returned by CompareGolden when snapshot differs from golden file
+	ErrSnapshotMismatch = errors.New("span snapshot differs from golden file")
+
+	port = regexp.MustCompile(`((?:[0-9]{1,3}\.){3}[0-9]{1,3}|localhost|\]):[0-9]+`)
+	line = regexp.MustCompile(`\.go:[0-9]+`)
+	ts   = regexp.MustCompile(`[0-9]{4}-[0-9]{2}-[0-9]{2}T[0-9:.]+(Z|[+-][0-9:]+)`)
+)
+
+// Normalize replaces timestamps, durations, identifiers and ports with placeholders
+func Normalize(key string, value interface{}) interface{} {
+	switch value.(type) {
+	case time.Time:
+		return "<time>"
+	case time.Duration:
+		return "<duration>"
+	}
+	switch {
+	case strings.HasSuffix(key, "_ms"), strings.HasSuffix(key, "latency"):
+		return "<duration>"
+	case strings.HasSuffix(key, "_id"), strings.HasSuffix(key, "ID"), key == "session", key == "tx":
+		return "<id>"
+	}
+	switch v := value.(type) {
+	case fmt.Stringer:
+		// fmt.Stringer values of ydb-go-sdk fields are kept by tracers as is
+		return normalizeString(v.String())
+	case string:
+		return normalizeString(v)
+	case []string:
+		// endpoint lists of driver spans
+		normalized := make([]string, len(v))
+		for i, s := range v {
+			normalized[i] = normalizeString(s)
+		}
+
+		return normalized
+	default:
+		return value
+	}
+}
+
+func normalizeString(s string) string {
+	s = port.ReplaceAllString(s, "$1:<port>")
+	s = line.ReplaceAllString(s, ".go:<line>")
+
+	return ts.ReplaceAllString(s, "<time>")
+}
+
+// Snapshot formats finished spans as tree which is stable between runs: tags and log fields are
+// normalized and sorted, siblings are sorted by their formatted subtrees.
+// Normalize is used if normalizer is nil.
+func (t *Tracer) Snapshot(normalizer Normalizer) string {
+	if normalizer == nil {
+		normalizer = Normalize
+	}
+	spans := t.Spans()
+	ids := make(map[int]bool, len(spans))
+	for _, s := range spans {
+		ids[s.ID] = true
+	}
+	var roots []string
+	for _, s := range spans {
+		if !ids[s.ParentID] {
+			roots = append(roots, snapshot(spans, s, 0, normalizer))
+		}
+	}
+	sort.Strings(roots)
+
+	return strings.Join(roots, "")
+}
+
+func snapshot(spans []Span, s Span, depth int, normalizer Normalizer) string {
+	indent := strings.Repeat("  ", depth)
+	var b strings.Builder
+	b.WriteString(indent + s.Name + "\n")
+	for _, k := range sortedKeys(s.Tags) {
+		fmt.Fprintf(&b, "%s  #%s=%v\n", indent, k, normalizer(k, s.Tags[k]))
+	}
+	for _, l := range s.Logs {
+		fields := make([]string, 0, len(l.Fields))
+		for _, k := range sortedKeys(l.Fields) {
+			fields = append(fields, fmt.Sprintf("%s=%v", k, normalizer(k, l.Fields[k])))
+		}
+		fmt.Fprintf(&b, "%s  @%s\n", indent, strings.Join(fields, " "))
+	}
+	var c []string
+	for _, child := range children(spans, s) {
+		c = append(c, snapshot(spans, child, depth+1, normalizer))
+	}
+	sort.Strings(c)
+	b.WriteString(strings.Join(c, ""))
+
+	return b.String()
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// CompareGolden compares snapshot with golden file at path, or rewrites golden file if update is true.
+// Mismatch is reported as ErrSnapshotMismatch with first differing line.
+func CompareGolden(path, snapshot string, update bool) error {
+	if update {
+		return os.WriteFile(path, []byte(snapshot), 0o600)
+	}
+	golden, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	want := strings.Split(string(golden), "\n")
+	got := strings.Split(snapshot, "\n")
+	for i := 0; i < len(want) || i < len(got); i++ {
+		var w, g string
+		if i < len(want) {
+			w = want[i]
+		}
+		if i < len(got) {
+			g = got[i]
+		}
+		if w != g {
+			return fmt.Errorf("%w: %s:%d: want %q, got %q", ErrSnapshotMismatch, path, i+1, w, g)
+		}
+	}
+
+	return nil
+}
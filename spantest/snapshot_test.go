@@ -0,0 +1,119 @@
+package spantest
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/opentracing/opentracing-go"
+)
+
+type address string
+
+func (a address) String() string {
+	return string(a)
+}
+
+func TestNormalize(t *testing.T) {
+	for _, tt := range []struct {
+		name  string
+		key   string
+		value interface{}
+		want  interface{}
+	}{
+		{"time", "t", time.Now(), "<time>"},
+		{"duration", "d", time.Second, "<duration>"},
+		{"millis key", "ydb.deadline_budget_ms", 1.5, "<duration>"},
+		{"latency key", "ydb.topic.latency", 1.5, "<duration>"},
+		{"id key", "ydb.session_id", "session-1", "<id>"},
+		{"ID key", "nodeID", 1, "<id>"},
+		{"session key", "session", "s", "<id>"},
+		{"tx key", "tx", "t", "<id>"},
+		{"ipv4 port", "address", "127.0.0.1:2135", "127.0.0.1:<port>"},
+		{"localhost port", "address", "grpc://localhost:2135/local", "grpc://localhost:<port>/local"},
+		{"ipv6 port", "address", "[::1]:2135", "[::1]:<port>"},
+		{"source line", "stack", "main.go:42", "main.go:<line>"},
+		{"timestamp", "at", "at 2024-02-03T04:05:06.007Z", "at <time>"},
+		{"timestamp offset", "at", "2024-02-03T04:05:06+03:00", "<time>"},
+		{"stringer", "address", address("127.0.0.1:2135"), "127.0.0.1:<port>"},
+		{"strings", "endpoints", []string{"127.0.0.1:1@a", "[::1]:2@b"}, []string{"127.0.0.1:<port>@a", "[::1]:<port>@b"}},
+		{"int", "count", 3, 3},
+		{"bool", "ok", true, true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Normalize(tt.key, tt.value); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Normalize(%q, %#v) = %#v, want %#v", tt.key, tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	// the same trees recorded in different order with different values of normalized tags
+	first := New()
+	record(first, node{name: "root", tags: opentracing.Tags{"b": 2, "a": "127.0.0.1:1"}, children: []node{
+		{name: "child", tags: opentracing.Tags{"n": 1}},
+		{name: "child", tags: opentracing.Tags{"n": 2}},
+	}}, nil)
+	record(first, node{name: "other"}, nil)
+	second := New()
+	record(second, node{name: "other"}, nil)
+	record(second, node{name: "root", tags: opentracing.Tags{"a": "127.0.0.1:2", "b": 2}, children: []node{
+		{name: "child", tags: opentracing.Tags{"n": 2}},
+		{name: "child", tags: opentracing.Tags{"n": 1}},
+	}}, nil)
+
+	want := "other\n" +
+		"root\n" +
+		"  #a=127.0.0.1:<port>\n" +
+		"  #b=2\n" +
+		"  child\n" +
+		"    #n=1\n" +
+		"  child\n" +
+		"    #n=2\n"
+	if got := first.Snapshot(nil); got != want {
+		t.Errorf("Snapshot() = %q, want %q", got, want)
+	}
+	if got := second.Snapshot(nil); got != want {
+		t.Errorf("Snapshot() of reordered spans = %q, want %q", got, want)
+	}
+}
+
+func TestSnapshotLogsAndNormalizer(t *testing.T) {
+	tracer := New()
+	s := tracer.StartSpan("root")
+	s.LogKV("event", "retry", "ydb.session_id", "session-1")
+	s.Finish()
+
+	got := tracer.Snapshot(func(key string, value interface{}) interface{} {
+		if key == "event" {
+			return "<event>"
+		}
+
+		return value
+	})
+	if want := "root\n  @event=<event> ydb.session_id=session-1\n"; got != want {
+		t.Errorf("Snapshot() = %q, want %q", got, want)
+	}
+}
+
+func TestCompareGolden(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "spans.golden")
+	if err := CompareGolden(path, "root\n", false); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("CompareGolden() of missing file error = %v, want %v", err, os.ErrNotExist)
+	}
+	if err := CompareGolden(path, "root\n  child\n", true); err != nil {
+		t.Fatalf("CompareGolden() update error = %v", err)
+	}
+	if err := CompareGolden(path, "root\n  child\n", false); err != nil {
+		t.Errorf("CompareGolden() of same snapshot error = %v", err)
+	}
+	for _, snapshot := range []string{"root\n  other\n", "root\n", "root\n  child\n  extra\n"} {
+		if err := CompareGolden(path, snapshot, false); !errors.Is(err, ErrSnapshotMismatch) {
+			t.Errorf("CompareGolden(%q) error = %v, want %v", snapshot, err, ErrSnapshotMismatch)
+		}
+	}
+}
@@ -0,0 +1,250 @@
+// Package spantest provides recording tracer for tests of code instrumented with ydb-go-sdk-opentracing.
+package spantest
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/opentracing/opentracing-go/mocktracer"
+)
+
+type (
+	// Tracer is an opentracing.Tracer which keeps finished spans in memory.
+	// Pass it to ydb.WithTracer and inspect spans with Spans or assertion helpers.
+	Tracer struct {
+		*mocktracer.MockTracer
+	}
+	// Span is a finished span
+	Span struct {
+		ID       int
+		ParentID int
+		TraceID  int
+		Name     string
+		Tags     map[string]interface{}
+		Logs     []Log
+		Start    time.Time
+		Duration time.Duration
+	}
+	// Log is a log record of span with fields formatted as strings
+	Log struct {
+		Time   time.Time
+		Fields map[string]string
+	}
+	// Shape is an expected span tree: span with Name has children matching each of Children.
+	// Actual spans may have more children than expected.
+	Shape struct {
+		Name     string
+		Children []Shape
+	}
+	// TestingT is a subset of testing.TB used by assertion helpers
+	TestingT interface {
+		Helper()
+		Errorf(format string, args ...interface{})
+	}
+)
+
+// New makes recording tracer
+func New() *Tracer {
+	return &Tracer{MockTracer: mocktracer.New()}
+}
+
+// Spans returns finished spans in order of finish
+func (t *Tracer) Spans() []Span {
+	finished := t.FinishedSpans()
+	spans := make([]Span, 0, len(finished))
+	for _, s := range finished {
+		spans = append(spans, toSpan(s))
+	}
+
+	return spans
+}
+
+// Named returns finished spans with given name
+func (t *Tracer) Named(name string) []Span {
+	var spans []Span
+	for _, s := range t.Spans() {
+		if s.Name == name {
+			spans = append(spans, s)
+		}
+	}
+
+	return spans
+}
+
+// Children returns finished spans which are children of given span
+func (t *Tracer) Children(parent Span) []Span {
+	return children(t.Spans(), parent)
+}
+
+// Has reports whether there is a finished span with given name and all given tags
+func (t *Tracer) Has(name string, tags map[string]interface{}) bool {
+	_, has := t.find(name, tags)
+
+	return has
+}
+
+// AssertSpan fails test when there is no finished span with given name and all given tags
+func (t *Tracer) AssertSpan(tt TestingT, name string, tags map[string]interface{}) (Span, bool) {
+	tt.Helper()
+	s, has := t.find(name, tags)
+	if !has {
+		tt.Errorf("no span %q with tags %v, finished spans:\n%s", name, tags, t)
+	}
+
+	return s, has
+}
+
+// AssertNoSpan fails test when there is finished span with given name
+func (t *Tracer) AssertNoSpan(tt TestingT, name string) bool {
+	tt.Helper()
+	if spans := t.Named(name); len(spans) > 0 {
+		tt.Errorf("unexpected span %q, finished spans:\n%s", name, t)
+
+		return false
+	}
+
+	return true
+}
+
+// AssertTree fails test when no finished span matches shape
+func (t *Tracer) AssertTree(tt TestingT, want Shape) bool {
+	tt.Helper()
+	spans := t.Spans()
+	for _, s := range spans {
+		if matches(spans, s, want) {
+			return true
+		}
+	}
+	tt.Errorf("no span tree matches\n%sfinished spans:\n%s", want, t)
+
+	return false
+}
+
+// String formats finished spans as indented tree
+func (t *Tracer) String() string {
+	spans := t.Spans()
+	ids := make(map[int]bool, len(spans))
+	for _, s := range spans {
+		ids[s.ID] = true
+	}
+	var b strings.Builder
+	for _, s := range spans {
+		if !ids[s.ParentID] {
+			writeTree(&b, spans, s, 0)
+		}
+	}
+
+	return b.String()
+}
+
+// String formats shape as indented tree
+func (s Shape) String() string {
+	var b strings.Builder
+	s.write(&b, 0)
+
+	return b.String()
+}
+
+func (s Shape) write(b *strings.Builder, depth int) {
+	b.WriteString(strings.Repeat("  ", depth) + s.Name + "\n")
+	for _, c := range s.Children {
+		c.write(b, depth+1)
+	}
+}
+
+func (t *Tracer) find(name string, tags map[string]interface{}) (Span, bool) {
+	for _, s := range t.Named(name) {
+		if hasTags(s, tags) {
+			return s, true
+		}
+	}
+
+	return Span{}, false
+}
+
+func hasTags(s Span, tags map[string]interface{}) bool {
+	for k, v := range tags {
+		actual, has := s.Tags[k]
+		if !has || fmt.Sprint(actual) != fmt.Sprint(v) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func children(spans []Span, parent Span) []Span {
+	var c []Span
+	for _, s := range spans {
+		if s.ParentID == parent.ID && s.TraceID == parent.TraceID {
+			c = append(c, s)
+		}
+	}
+
+	return c
+}
+
+// matches checks that each expected child is matched by distinct actual child
+func matches(spans []Span, s Span, want Shape) bool {
+	if s.Name != want.Name {
+		return false
+	}
+	actual := children(spans, s)
+	used := make([]bool, len(actual))
+	var assign func(i int) bool
+	assign = func(i int) bool {
+		if i == len(want.Children) {
+			return true
+		}
+		for j, c := range actual {
+			if used[j] || !matches(spans, c, want.Children[i]) {
+				continue
+			}
+			used[j] = true
+			if assign(i + 1) {
+				return true
+			}
+			used[j] = false
+		}
+
+		return false
+	}
+
+	return assign(0)
+}
+
+func writeTree(b *strings.Builder, spans []Span, s Span, depth int) {
+	keys := sortedKeys(s.Tags)
+	tags := make([]string, 0, len(keys))
+	for _, k := range keys {
+		tags = append(tags, fmt.Sprintf("%s=%v", k, s.Tags[k]))
+	}
+	b.WriteString(strings.Repeat("  ", depth) + s.Name + " {" + strings.Join(tags, ", ") + "}\n")
+	for _, c := range children(spans, s) {
+		writeTree(b, spans, c, depth+1)
+	}
+}
+
+func toSpan(s *mocktracer.MockSpan) Span {
+	records := s.Logs()
+	logs := make([]Log, 0, len(records))
+	for _, r := range records {
+		fields := make(map[string]string, len(r.Fields))
+		for _, f := range r.Fields {
+			fields[f.Key] = f.ValueString
+		}
+		logs = append(logs, Log{Time: r.Timestamp, Fields: fields})
+	}
+
+	return Span{
+		ID:       s.SpanContext.SpanID,
+		ParentID: s.ParentID,
+		TraceID:  s.SpanContext.TraceID,
+		Name:     s.OperationName,
+		Tags:     s.Tags(),
+		Logs:     logs,
+		Start:    s.StartTime,
+		Duration: s.FinishTime.Sub(s.StartTime),
+	}
+}
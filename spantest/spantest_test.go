@@ -0,0 +1,141 @@
+package spantest
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/opentracing/opentracing-go"
+)
+
+// recordingT is a TestingT which keeps failures instead of failing test
+type recordingT struct {
+	errors []string
+}
+
+func (t *recordingT) Helper() {}
+
+func (t *recordingT) Errorf(format string, args ...interface{}) {
+	t.errors = append(t.errors, fmt.Sprintf(format, args...))
+}
+
+// node is a span tree to record: span with tags has children
+type node struct {
+	name     string
+	tags     opentracing.Tags
+	children []node
+}
+
+func record(tracer *Tracer, n node, parent opentracing.Span) {
+	opts := []opentracing.StartSpanOption{n.tags}
+	if parent != nil {
+		opts = append(opts, opentracing.ChildOf(parent.Context()))
+	}
+	s := tracer.StartSpan(n.name, opts...)
+	for _, c := range n.children {
+		record(tracer, c, s)
+	}
+	s.Finish()
+}
+
+func recorded(n node) *Tracer {
+	tracer := New()
+	record(tracer, n, nil)
+
+	return tracer
+}
+
+func TestAssertTree(t *testing.T) {
+	// first child "a" matches both expected children, so greedy matching of the first
+	// expected child takes it and leaves nothing for the second one
+	tree := node{name: "root", children: []node{
+		{name: "a", children: []node{{name: "b"}}},
+		{name: "a"},
+	}}
+	for _, tt := range []struct {
+		name string
+		want Shape
+		ok   bool
+	}{
+		{
+			name: "backtracking",
+			want: Shape{Name: "root", Children: []Shape{{Name: "a"}, {Name: "a", Children: []Shape{{Name: "b"}}}}},
+			ok:   true,
+		},
+		{
+			name: "subset of children",
+			want: Shape{Name: "root", Children: []Shape{{Name: "a"}}},
+			ok:   true,
+		},
+		{
+			name: "subtree",
+			want: Shape{Name: "a", Children: []Shape{{Name: "b"}}},
+			ok:   true,
+		},
+		{
+			name: "distinct children",
+			want: Shape{Name: "root", Children: []Shape{{Name: "a"}, {Name: "a"}, {Name: "a"}}},
+		},
+		{
+			name: "missing grandchild",
+			want: Shape{Name: "root", Children: []Shape{{Name: "a", Children: []Shape{{Name: "c"}}}}},
+		},
+		{
+			name: "wrong root",
+			want: Shape{Name: "b", Children: []Shape{{Name: "a"}}},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			rt := &recordingT{}
+			if ok := recorded(tree).AssertTree(rt, tt.want); ok != tt.ok {
+				t.Errorf("AssertTree(%v) = %v, want %v", tt.want, ok, tt.ok)
+			}
+			if failed := len(rt.errors) > 0; failed == tt.ok {
+				t.Errorf("AssertTree(%v) errors = %v", tt.want, rt.errors)
+			}
+		})
+	}
+}
+
+func TestAssertTreeOtherTrace(t *testing.T) {
+	tracer := New()
+	record(tracer, node{name: "root"}, nil)
+	record(tracer, node{name: "other", children: []node{{name: "a"}}}, nil)
+	if tracer.AssertTree(&recordingT{}, Shape{Name: "root", Children: []Shape{{Name: "a"}}}) {
+		t.Error("AssertTree() matched child of span from other trace")
+	}
+}
+
+func TestHasTags(t *testing.T) {
+	s := Span{Tags: map[string]interface{}{"int": 1, "string": "s", "bool": true}}
+	for _, tt := range []struct {
+		name string
+		tags map[string]interface{}
+		want bool
+	}{
+		{"no tags", nil, true},
+		{"same types", map[string]interface{}{"int": 1, "string": "s"}, true},
+		{"formatted values", map[string]interface{}{"int": "1", "bool": "true"}, true},
+		{"other value", map[string]interface{}{"int": 2}, false},
+		{"missing tag", map[string]interface{}{"missing": ""}, false},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := hasTags(s, tt.tags); got != tt.want {
+				t.Errorf("hasTags(%v) = %v, want %v", tt.tags, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAssertSpan(t *testing.T) {
+	tracer := recorded(node{name: "root", tags: opentracing.Tags{"k": "v"}})
+	rt := &recordingT{}
+	if _, ok := tracer.AssertSpan(rt, "root", map[string]interface{}{"k": "v"}); !ok || len(rt.errors) > 0 {
+		t.Errorf("AssertSpan() = %v, errors %v", ok, rt.errors)
+	}
+	if _, ok := tracer.AssertSpan(rt, "root", map[string]interface{}{"k": "w"}); ok || len(rt.errors) != 1 {
+		t.Errorf("AssertSpan() = %v, errors %v", ok, rt.errors)
+	}
+	if tracer.AssertNoSpan(rt, "root") || len(rt.errors) != 2 {
+		t.Errorf("AssertNoSpan() errors %v", rt.errors)
+	}
+}
@@ -0,0 +1,461 @@
+package ydb
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/ydb-platform/ydb-go-genproto/protos/Ydb_Table"
+	"github.com/ydb-platform/ydb-go-sdk/v3"
+	"github.com/ydb-platform/ydb-go-sdk/v3/table"
+	"github.com/ydb-platform/ydb-go-sdk/v3/trace"
+)
+
+const (
+	sqlQueryOperationName = "sql.query"
+	sqlExecOperationName  = "sql.exec"
+	sqlTxOperationName    = "sql.tx"
+
+	queryModeTag = "ydb.query_mode"
+	txControlTag = "ydb.tx_control"
+
+	// txQueryMode is mode of statements of database/sql transaction
+	txQueryMode = "data"
+)
+
+var (
+	_ driver.Connector          = (*sqlConnector)(nil)
+	_ driver.Conn               = (*sqlConn)(nil)
+	_ driver.ConnPrepareContext = (*sqlConn)(nil)
+	_ driver.ConnBeginTx        = (*sqlConn)(nil)
+	_ driver.ExecerContext      = (*sqlConn)(nil)
+	_ driver.QueryerContext     = (*sqlConn)(nil)
+	_ driver.Pinger             = (*sqlConn)(nil)
+	_ driver.Validator          = (*sqlConn)(nil)
+	_ driver.NamedValueChecker  = (*sqlConn)(nil)
+	_ driver.Tx                 = (*sqlTx)(nil)
+	_ driver.Stmt               = (*sqlStmt)(nil)
+	_ driver.StmtQueryContext   = (*sqlStmt)(nil)
+	_ driver.StmtExecContext    = (*sqlStmt)(nil)
+	_ driver.NamedValueChecker  = (*sqlStmt)(nil)
+)
+
+type (
+	sqlConnector struct {
+		connector driver.Connector
+		cfg       *adapter
+	}
+	sqlConn struct {
+		conn driver.Conn
+		cfg  *adapter
+		tx   *sqlTx
+	}
+	sqlTx struct {
+		*txSpan
+
+		tx   driver.Tx
+		ctx  context.Context //nolint:containedctx
+		conn *sqlConn
+	}
+	sqlStmt struct {
+		stmt  driver.Stmt
+		conn  *sqlConn
+		query string
+	}
+	sqlSpanKey      struct{}
+	sqlTxControlKey struct{}
+)
+
+// WrapConnector wraps database/sql connector (ydb.Connector, for example) and starts
+// sql.query, sql.exec and sql.tx spans around calls of connections and prepared statements.
+// Spans of YDB driver are nested under them.
+// Connections of wrapped connector hide connections of ydb-go-sdk, so ydb.Unwrap fails for *sql.Conn:
+// use ydb.Unwrap of *sql.DB instead.
+func WrapConnector(connector driver.Connector, opts ...Option) driver.Connector {
+	return &sqlConnector{
+		connector: connector,
+		cfg:       newAdapter(opts...),
+	}
+}
+
+// OpenDB is a shorthand for sql.OpenDB(WrapConnector(connector, opts...))
+func OpenDB(connector driver.Connector, opts ...Option) *sql.DB {
+	return sql.OpenDB(WrapConnector(connector, opts...))
+}
+
+// WithTxControl is a shorthand for ydb.WithTxControl which also
+// tags sql.query, sql.exec and sql.tx spans with transaction control.
+func WithTxControl(ctx context.Context, txc *table.TransactionControl) context.Context {
+	return context.WithValue(ydb.WithTxControl(ctx, txc), sqlTxControlKey{}, txc)
+}
+
+func txControlName(txc *table.TransactionControl) string {
+	if txc == nil {
+		return ""
+	}
+	desc := txc.Desc()
+	name := "tx"
+	if desc.GetTxId() == "" {
+		name = txModeName(desc.GetBeginTx())
+	}
+	if desc.GetCommitTx() {
+		return name + "+commit"
+	}
+
+	return name
+}
+
+func txModeName(settings *Ydb_Table.TransactionSettings) string {
+	switch {
+	case settings.GetSerializableReadWrite() != nil:
+		return "serializable_read_write"
+	case settings.GetOnlineReadOnly() != nil:
+		return "online_read_only"
+	case settings.GetStaleReadOnly() != nil:
+		return "stale_read_only"
+	case settings.GetSnapshotReadOnly() != nil:
+		return "snapshot_read_only"
+	default:
+		return ""
+	}
+}
+
+func (c *sqlConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	cc, err := c.connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &sqlConn{
+		conn: cc,
+		cfg:  c.cfg,
+	}, nil
+}
+
+func (c *sqlConnector) Driver() driver.Driver {
+	return c.connector.Driver()
+}
+
+func (c *sqlConn) startSpan(ctx context.Context, operationName, query string) (context.Context, opentracing.Span) {
+	opts := []opentracing.StartSpanOption{
+		ext.SpanKindRPCClient,
+		opentracing.Tag{Key: string(ext.DBType), Value: "ydb"},
+		opentracing.Tag{Key: string(ext.DBStatement), Value: query},
+	}
+	if c.tx != nil {
+		opts = append(opts, opentracing.ChildOf(c.tx.span.Context()))
+		// statement span is a descendant of transaction, so transaction hooks keep it as parent
+		ctx = context.WithValue(ctx, txSpanKey{}, c.tx.txSpan)
+	} else if parent := c.cfg.parent(ctx); parent != nil {
+		opts = append(opts, opentracing.ChildOf(parent))
+	} else if c.cfg.implicitRoot {
+		tags := opentracing.Tags{}
+		c.cfg.rootTags(tags)
+		opts = append(opts, tags)
+	}
+	if txc, has := ctx.Value(sqlTxControlKey{}).(*table.TransactionControl); has {
+		opts = append(opts, opentracing.Tag{Key: txControlTag, Value: txControlName(txc)})
+	}
+	if attempt := retryAttempt(ctx); attempt > 0 {
+		opts = append(opts, opentracing.Tag{Key: retryAttemptTag, Value: attempt})
+	}
+	opts = append(opts, opentracing.StartTime(c.cfg.now()))
+	s := c.cfg.tracer.StartSpan(operationName, opts...)
+
+	return context.WithValue(opentracing.ContextWithSpan(ctx, s), sqlSpanKey{}, s), s
+}
+
+func (c *sqlConn) finishSpan(s opentracing.Span, err error) {
+	if err != nil && !errors.Is(err, driver.ErrSkip) {
+		setError(s, err)
+	}
+	c.cfg.finish(s)
+}
+
+func (c *sqlConn) comment(ctx context.Context, query string) string {
+	if !c.cfg.queryComments {
+		return query
+	}
+
+	return c.cfg.comment(ctx, query)
+}
+
+func (c *sqlConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	queryer, ok := c.conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	ctx, s := c.startSpan(ctx, sqlQueryOperationName, query)
+	rows, err := queryer.QueryContext(ctx, c.comment(ctx, query), args)
+	c.finishSpan(s, err)
+
+	return rows, err
+}
+
+func (c *sqlConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	execer, ok := c.conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	ctx, s := c.startSpan(ctx, sqlExecOperationName, query)
+	res, err := execer.ExecContext(ctx, c.comment(ctx, query), args)
+	c.finishSpan(s, err)
+
+	return res, err
+}
+
+func (c *sqlConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	beginner, ok := c.conn.(driver.ConnBeginTx)
+	if !ok {
+		return nil, errors.New("ydb: driver connection does not implement driver.ConnBeginTx")
+	}
+	tags := opentracing.Tags{
+		string(ext.DBType): "ydb",
+		"sql.isolation":    sql.IsolationLevel(opts.Isolation).String(),
+		"sql.read_only":    opts.ReadOnly,
+	}
+	if isolation := sqlTxIsolation(opts); isolation != "" {
+		tags[txIsolationTag] = isolation
+	}
+	if txc, has := ctx.Value(sqlTxControlKey{}).(*table.TransactionControl); has {
+		tags[txControlTag] = txControlName(txc)
+	}
+	ctx, ts := startTxSpan(ctx, c.cfg, sqlTxOperationName, tags)
+	// transaction is ended with Commit or Rollback of database/sql transaction
+	ts.owned = true
+	tx, err := beginner.BeginTx(ctx, opts)
+	if err != nil {
+		ts.finish(txOutcomeRollback, err)
+
+		return nil, err
+	}
+	c.tx = &sqlTx{
+		tx:     tx,
+		ctx:    ctx,
+		txSpan: ts,
+		conn:   c,
+	}
+
+	return c.tx, nil
+}
+
+// sqlTxIsolation mirrors mapping of database/sql transaction options to YDB transaction modes
+func sqlTxIsolation(opts driver.TxOptions) string {
+	switch sql.IsolationLevel(opts.Isolation) {
+	case sql.LevelDefault, sql.LevelSerializable:
+		if !opts.ReadOnly {
+			return "serializable_read_write"
+		}
+	case sql.LevelSnapshot:
+		if opts.ReadOnly {
+			return "snapshot_read_only"
+		}
+	}
+
+	return ""
+}
+
+// PrepareContext does not comment query: prepared statement outlives span active at prepare time
+// and is executed with server-side query cache, so it is never commented (see WithQueryComments)
+func (c *sqlConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	var (
+		stmt driver.Stmt
+		err  error
+	)
+	if preparer, ok := c.conn.(driver.ConnPrepareContext); ok {
+		stmt, err = preparer.PrepareContext(ctx, query)
+	} else {
+		stmt, err = c.conn.Prepare(query)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &sqlStmt{stmt: stmt, conn: c, query: query}, nil
+}
+
+func (c *sqlConn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+func (c *sqlConn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *sqlConn) Close() error {
+	return c.conn.Close()
+}
+
+func (c *sqlConn) Ping(ctx context.Context) error {
+	if pinger, ok := c.conn.(driver.Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+
+	return nil
+}
+
+func (c *sqlConn) IsValid() bool {
+	if validator, ok := c.conn.(driver.Validator); ok {
+		return validator.IsValid()
+	}
+
+	return true
+}
+
+func (c *sqlConn) CheckNamedValue(value *driver.NamedValue) error {
+	if checker, ok := c.conn.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(value)
+	}
+
+	return driver.ErrSkip
+}
+
+func (s *sqlStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	ctx, span := s.conn.startSpan(ctx, sqlQueryOperationName, s.query)
+	var (
+		rows driver.Rows
+		err  error
+	)
+	if queryer, ok := s.stmt.(driver.StmtQueryContext); ok {
+		rows, err = queryer.QueryContext(ctx, args)
+	} else {
+		var values []driver.Value
+		if values, err = namedValues(args); err == nil {
+			rows, err = s.stmt.Query(values) //nolint:staticcheck
+		}
+	}
+	s.conn.finishSpan(span, err)
+
+	return rows, err
+}
+
+func (s *sqlStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	ctx, span := s.conn.startSpan(ctx, sqlExecOperationName, s.query)
+	var (
+		res driver.Result
+		err error
+	)
+	if execer, ok := s.stmt.(driver.StmtExecContext); ok {
+		res, err = execer.ExecContext(ctx, args)
+	} else {
+		var values []driver.Value
+		if values, err = namedValues(args); err == nil {
+			res, err = s.stmt.Exec(values) //nolint:staticcheck
+		}
+	}
+	s.conn.finishSpan(span, err)
+
+	return res, err
+}
+
+func (s *sqlStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return s.QueryContext(context.Background(), valuesNamed(args))
+}
+
+func (s *sqlStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return s.ExecContext(context.Background(), valuesNamed(args))
+}
+
+func (s *sqlStmt) NumInput() int {
+	return s.stmt.NumInput()
+}
+
+func (s *sqlStmt) Close() error {
+	return s.stmt.Close()
+}
+
+func (s *sqlStmt) CheckNamedValue(value *driver.NamedValue) error {
+	if checker, ok := s.stmt.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(value)
+	}
+
+	return driver.ErrSkip
+}
+
+// namedValues mirrors conversion of database/sql for statements without context methods
+func namedValues(args []driver.NamedValue) ([]driver.Value, error) {
+	values := make([]driver.Value, len(args))
+	for i, arg := range args {
+		if arg.Name != "" {
+			return nil, errors.New("ydb: driver statement does not support the use of Named Parameters")
+		}
+		values[i] = arg.Value
+	}
+
+	return values, nil
+}
+
+func valuesNamed(args []driver.Value) []driver.NamedValue {
+	named := make([]driver.NamedValue, len(args))
+	for i, v := range args {
+		named[i] = driver.NamedValue{Ordinal: i + 1, Value: v}
+	}
+
+	return named
+}
+
+func (tx *sqlTx) end() {
+	tx.conn.tx = nil
+	if loop := retryLoopFromContext(tx.ctx); loop != nil {
+		tx.span.SetTag(retryAttemptTag, int(loop.attempts.Load()))
+	}
+}
+
+func (tx *sqlTx) Commit() error {
+	tx.end()
+	err := tx.tx.Commit()
+	tx.finish(txOutcomeCommit, err)
+
+	return err
+}
+
+func (tx *sqlTx) Rollback() error {
+	tx.end()
+	err := tx.tx.Rollback()
+	tx.finish(txOutcomeRollback, err)
+
+	return err
+}
+
+func databaseSQL(cfg *adapter) (t trace.DatabaseSQL) {
+	t.OnConnQuery = func(info trace.DatabaseSQLConnQueryStartInfo) func(trace.DatabaseSQLConnQueryDoneInfo) {
+		if s, has := (*info.Context).Value(sqlSpanKey{}).(opentracing.Span); has {
+			s.SetTag(queryModeTag, info.Mode)
+		}
+
+		return nil
+	}
+	// trace of transaction statements has no query mode: database/sql transaction executes only data queries
+	t.OnTxQuery = func(info trace.DatabaseSQLTxQueryStartInfo) func(trace.DatabaseSQLTxQueryDoneInfo) {
+		if s, has := (*info.Context).Value(sqlSpanKey{}).(opentracing.Span); has {
+			s.SetTag(queryModeTag, txQueryMode)
+		}
+
+		return nil
+	}
+	t.OnTxExec = func(info trace.DatabaseSQLTxExecStartInfo) func(trace.DatabaseSQLTxExecDoneInfo) {
+		if s, has := (*info.Context).Value(sqlSpanKey{}).(opentracing.Span); has {
+			s.SetTag(queryModeTag, txQueryMode)
+		}
+
+		return nil
+	}
+	t.OnConnExec = func(info trace.DatabaseSQLConnExecStartInfo) func(trace.DatabaseSQLConnExecDoneInfo) {
+		if s, has := (*info.Context).Value(sqlSpanKey{}).(opentracing.Span); has {
+			s.SetTag(queryModeTag, info.Mode)
+		}
+		done := cfg.ddlQuery(*info.Context, info.Query)
+		if done == nil {
+			return nil
+		}
+
+		return func(info trace.DatabaseSQLConnExecDoneInfo) {
+			done(info.Error)
+		}
+	}
+
+	return t
+}
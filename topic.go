@@ -0,0 +1,144 @@
+package ydb
+
+import (
+	"context"
+	"math/rand"
+	"time"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/opentracing/opentracing-go/log"
+	"github.com/ydb-platform/ydb-go-sdk/v3/topic/topicreader"
+	"github.com/ydb-platform/ydb-go-sdk/v3/topic/topicwriter"
+)
+
+const (
+	topicWriteOperationName = "ydb.topic.write"
+	topicReadOperationName  = "ydb.topic.read"
+
+	topicPartitionTag     = "ydb.topic.partition"
+	topicOffsetTag        = "ydb.topic.offset"
+	topicSeqNoTag         = "ydb.topic.seq_no"
+	topicLatencyTag       = "ydb.topic.end_to_end_latency_ms"
+	topicCommitLatencyTag = "ydb.topic.commit_latency_ms"
+)
+
+var (
+	_ opentracing.TextMapWriter = messageMetadata(nil)
+	_ opentracing.TextMapReader = messageMetadata(nil)
+)
+
+type (
+	// Topics makes per-message spans for topic writers and readers.
+	// Writer injects span context into message metadata and reader continues the trace
+	// with consumer span which follows from producer span.
+	Topics struct {
+		cfg *adapter
+	}
+	messageMetadata map[string][]byte
+)
+
+func (m messageMetadata) Set(key, val string) {
+	m[key] = []byte(val)
+}
+
+func (m messageMetadata) ForeachKey(handler func(key, val string) error) error {
+	for k, v := range m {
+		if err := handler(k, string(v)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// NewTopics makes per-message tracing of topics with given options (see WithTopicSampleRate)
+func NewTopics(opts ...Option) *Topics {
+	return &Topics{
+		cfg: newAdapter(opts...),
+	}
+}
+
+func (t *Topics) sampled() bool {
+	return t.cfg.topicSampleRate >= 1 || rand.Float64() < t.cfg.topicSampleRate
+}
+
+// StartWrite starts producer span for message and injects its context into message metadata.
+// Span must be finished by caller after topicwriter.Writer.Write.
+func (t *Topics) StartWrite(ctx context.Context, topic string, msg *topicwriter.Message) (
+	context.Context, opentracing.Span,
+) {
+	if !t.sampled() {
+		return ctx, opentracing.NoopTracer{}.StartSpan(topicWriteOperationName)
+	}
+	tags := opentracing.Tags{
+		string(ext.MessageBusDestination): topic,
+	}
+	if msg.SeqNo != 0 {
+		tags[topicSeqNoTag] = msg.SeqNo
+	}
+	opts := []opentracing.StartSpanOption{ext.SpanKindProducer, tags}
+	// message may be buffered by caller, so span starts when message is created
+	if !msg.CreatedAt.IsZero() {
+		opts = append(opts, opentracing.StartTime(msg.CreatedAt))
+	}
+	s, ctx := t.cfg.startSpanFromContext(ctx, topicWriteOperationName, opts...)
+	if msg.Metadata == nil {
+		msg.Metadata = make(map[string][]byte)
+	}
+	if err := t.cfg.tracer.Inject(s.Context(), opentracing.TextMap, messageMetadata(msg.Metadata)); err != nil {
+		s.LogFields(log.Error(err))
+	}
+
+	return ctx, s
+}
+
+// StartRead starts consumer span for message read from topic. If message contains span context
+// of producer, consumer span follows from it.
+func (t *Topics) StartRead(ctx context.Context, msg *topicreader.Message) (context.Context, opentracing.Span) {
+	producer, err := t.cfg.tracer.Extract(opentracing.TextMap, messageMetadata(msg.Metadata))
+	if err != nil && !t.sampled() {
+		return ctx, opentracing.NoopTracer{}.StartSpan(topicReadOperationName)
+	}
+	tags := opentracing.Tags{
+		string(ext.MessageBusDestination): msg.Topic(),
+		topicPartitionTag:                 msg.PartitionID(),
+		topicOffsetTag:                    msg.Offset,
+		topicSeqNoTag:                     msg.SeqNo,
+	}
+	opts := []opentracing.StartSpanOption{ext.SpanKindConsumer, tags}
+	if parent := t.cfg.parent(ctx); parent != nil {
+		opts = append(opts, opentracing.ChildOf(parent))
+	} else {
+		t.cfg.rootTags(tags)
+	}
+	if producer != nil {
+		opts = append(opts, opentracing.FollowsFrom(producer))
+	}
+	if !msg.WrittenAt.IsZero() {
+		// write time is set by server, so latency is measured with wall clock instead of WithClock
+		opts = append(opts, opentracing.Tag{Key: topicLatencyTag, Value: millis(time.Since(msg.WrittenAt))})
+	}
+	s := t.cfg.tracer.StartSpan(topicReadOperationName, append(opts, opentracing.StartTime(t.cfg.now()))...)
+
+	return opentracing.ContextWithSpan(ctx, s), s
+}
+
+// Commit commits message with reader and tags consumer span from context with commit latency.
+// It must be called before consumer span is finished. Latency is meaningful only for reader with
+// topicoptions.CommitModeSync: in async mode Commit returns before commit is acknowledged by server.
+func (t *Topics) Commit(ctx context.Context, reader interface {
+	Commit(ctx context.Context, obj topicreader.CommitRangeGetter) error
+}, msg *topicreader.Message,
+) error {
+	start := t.cfg.now()
+	err := reader.Commit(ctx, msg)
+	if s := opentracing.SpanFromContext(ctx); s != nil {
+		s.SetTag(topicCommitLatencyTag, millis(t.cfg.now().Sub(start)))
+		if err != nil {
+			setError(s, err)
+		}
+	}
+
+	return err
+}
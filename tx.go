@@ -0,0 +1,408 @@
+package ydb
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/ydb-platform/ydb-go-sdk/v3"
+	"github.com/ydb-platform/ydb-go-sdk/v3/table"
+	"github.com/ydb-platform/ydb-go-sdk/v3/trace"
+)
+
+const (
+	txOperationName = "ydb.tx"
+
+	txIDTag         = "ydb.tx.id"
+	txIsolationTag  = "ydb.tx.isolation"
+	txOutcomeTag    = "ydb.tx.outcome"
+	txStatementsTag = "ydb.tx.statements"
+
+	txOutcomeCommit          = "commit"
+	txOutcomeRollback        = "rollback"
+	txOutcomeLockInvalidated = "lock-invalidated"
+	// txOutcomeUnknown is outcome of transaction which has no commit or rollback event and is not
+	// owned by retry loop: it is committed with the last statement or abandoned with its session
+	txOutcomeUnknown = "unknown"
+)
+
+type (
+	txSpanKey     struct{}
+	txSettingsKey struct{}
+	txSpan        struct {
+		span       opentracing.Span
+		cfg        *adapter
+		id         atomic.Pointer[string]
+		session    string
+		statements atomic.Int32
+		err        atomic.Pointer[error]
+		once       sync.Once
+		// attempt of retry loop which transaction is begun in, it ends with transaction
+		attempt *attemptSpan
+
+		// owned transaction is finished by its retry loop or database/sql transaction,
+		// not by release of its session
+		owned bool
+	}
+	// txRegistry keeps transactions in progress by transaction and session id. Transaction is
+	// removed on finish or with its session, so registry is bounded by number of sessions.
+	txRegistry struct {
+		mu       sync.Mutex
+		txs      map[string]*txSpan
+		sessions map[string]*txSpan
+	}
+)
+
+// txs is shared between adapters, because database/sql wrapper and driver traces
+// are configured separately, but must see the same transactions
+var txs = &txRegistry{
+	txs:      make(map[string]*txSpan),
+	sessions: make(map[string]*txSpan),
+}
+
+func (r *txRegistry) get(tx interface{ ID() string }) *txSpan {
+	if tx == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.txs[tx.ID()]
+}
+
+// put registers transaction and returns previous transaction of its session:
+// session has only one transaction, so previous one is ended
+func (r *txRegistry) put(id string, tx *txSpan) (prev *txSpan) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.txs[id] = tx
+	if tx.session == "" {
+		return nil
+	}
+	prev = r.sessions[tx.session]
+	r.sessions[tx.session] = tx
+
+	return prev
+}
+
+func (r *txRegistry) remove(id string, tx *txSpan) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.txs, id)
+	if tx.session != "" && r.sessions[tx.session] == tx {
+		delete(r.sessions, tx.session)
+	}
+}
+
+// session returns transaction of session
+func (r *txRegistry) session(session interface{ ID() string }) *txSpan {
+	if session == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.sessions[session.ID()]
+}
+
+// release ends transaction of session which is put back to pool or deleted
+func (r *txRegistry) release(session interface{ ID() string }) {
+	if tx := r.session(session); tx != nil {
+		tx.release()
+	}
+}
+
+// WithTxSettings remembers transaction settings passed to table.Client.DoTx with table.WithTxSettings,
+// so transaction span is tagged with isolation mode.
+//
+// Table and query traces of ydb-go-sdk don't report transaction settings, so ydb.tx.isolation is known
+// only from this context, from WithTxControl and from options of database/sql transactions. Spans of
+// query service transactions and of table transactions without WithTxSettings have no isolation tag.
+func WithTxSettings(ctx context.Context, settings *table.TransactionSettings) context.Context {
+	return context.WithValue(ctx, txSettingsKey{}, settings)
+}
+
+func txIsolation(ctx context.Context) string {
+	if settings, has := ctx.Value(txSettingsKey{}).(*table.TransactionSettings); has && settings != nil {
+		return txModeName(settings.Settings())
+	}
+	if txc, has := ctx.Value(sqlTxControlKey{}).(*table.TransactionControl); has && txc != nil {
+		return txModeName(txc.Desc().GetBeginTx())
+	}
+
+	return ""
+}
+
+func startTxSpan(ctx context.Context, cfg *adapter, operationName string, tags opentracing.Tags) (
+	context.Context, *txSpan,
+) {
+	if isolation := txIsolation(ctx); isolation != "" {
+		tags[txIsolationTag] = isolation
+	}
+	loop := retryLoopFromContext(ctx)
+	var attempt *attemptSpan
+	if loop != nil {
+		// transaction of retry loop is a child of its attempt
+		ctx, attempt = loop.openAttempt(ctx)
+	}
+	s, ctx := cfg.startSpanFromContext(ctx, operationName, tags)
+	tx := &txSpan{span: s, cfg: cfg, attempt: attempt}
+	if loop != nil {
+		tx.owned = true
+		loop.addTx(tx)
+	}
+
+	return context.WithValue(ctx, txSpanKey{}, tx), tx
+}
+
+func (tx *txSpan) begun(id string, err error) {
+	if err != nil {
+		tx.finish(txOutcomeRollback, err)
+
+		return
+	}
+	if id == "" || !tx.id.CompareAndSwap(nil, &id) {
+		return
+	}
+	tx.span.SetTag(txIDTag, id)
+	if prev := txs.put(id, tx); prev != nil {
+		prev.release()
+	}
+}
+
+// fail remembers error of statement or retry attempt of transaction
+func (tx *txSpan) fail(err error) {
+	if err != nil {
+		tx.err.Store(&err)
+	}
+}
+
+func (tx *txSpan) lastErr() error {
+	if err := tx.err.Load(); err != nil {
+		return *err
+	}
+
+	return nil
+}
+
+// release ends transaction which is not owned by retry loop when its session is released
+func (tx *txSpan) release() {
+	if tx.owned {
+		return
+	}
+	if err := tx.lastErr(); err != nil {
+		tx.finish(txOutcomeRollback, err)
+	} else {
+		tx.finish(txOutcomeUnknown, nil)
+	}
+}
+
+func (tx *txSpan) finish(outcome string, err error) {
+	tx.once.Do(func() {
+		if id := tx.id.Load(); id != nil {
+			txs.remove(*id, tx)
+		}
+		if err != nil {
+			if ydb.IsOperationErrorTransactionLocksInvalidated(err) {
+				outcome = txOutcomeLockInvalidated
+			}
+			setError(tx.span, err)
+		}
+		tx.span.SetTag(txOutcomeTag, outcome)
+		tx.span.SetTag(txStatementsTag, int(tx.statements.Load()))
+		tx.cfg.finish(tx.span)
+		if tx.attempt != nil {
+			if err == nil {
+				err = tx.lastErr()
+			}
+			tx.attempt.end(err)
+		}
+	})
+}
+
+// withTx makes transaction span parent of operation span started by spans package
+func withTx(ctx *context.Context, info interface{ ID() string }, statement bool) *txSpan {
+	tx := txs.get(info)
+	if tx == nil {
+		return nil
+	}
+	if statement {
+		tx.statements.Add(1)
+	}
+	if in, _ := (*ctx).Value(txSpanKey{}).(*txSpan); in != tx || (*ctx).Value(sqlSpanKey{}) == nil {
+		// operation keeps its parent only when it is under database/sql statement of transaction
+		*ctx = opentracing.ContextWithSpan(*ctx, tx.span)
+	}
+
+	return tx
+}
+
+func (cfg *adapter) beginTx(ctx *context.Context, session interface{ ID() string }) *txSpan {
+	if tx, has := (*ctx).Value(txSpanKey{}).(*txSpan); has && tx.id.Load() == nil {
+		// transaction span is already started by database/sql wrapper
+		return tx
+	}
+	var tx *txSpan
+	*ctx, tx = startTxSpan(*ctx, cfg, txOperationName, opentracing.Tags{})
+	if session != nil {
+		tx.session = session.ID()
+	}
+
+	return tx
+}
+
+// txTable must be registered before spans.WithTraces: it replaces context of transaction operations
+func txTable(cfg *adapter) (t trace.Table) {
+	t.OnTxBegin = func(info trace.TableTxBeginStartInfo) func(trace.TableTxBeginDoneInfo) {
+		tx := cfg.beginTx(info.Context, info.Session)
+
+		return func(info trace.TableTxBeginDoneInfo) {
+			var id string
+			if info.Tx != nil {
+				id = info.Tx.ID()
+			}
+			tx.begun(id, info.Error)
+		}
+	}
+	t.OnTxExecute = func(info trace.TableTransactionExecuteStartInfo) func(trace.TableTransactionExecuteDoneInfo) {
+		tx := withTx(info.Context, info.Tx, true)
+		if tx == nil {
+			return nil
+		}
+
+		return func(info trace.TableTransactionExecuteDoneInfo) {
+			tx.fail(info.Error)
+		}
+	}
+	t.OnTxExecuteStatement = func(
+		info trace.TableTransactionExecuteStatementStartInfo,
+	) func(trace.TableTransactionExecuteStatementDoneInfo) {
+		tx := withTx(info.Context, info.Tx, true)
+		if tx == nil {
+			return nil
+		}
+
+		return func(info trace.TableTransactionExecuteStatementDoneInfo) {
+			tx.fail(info.Error)
+		}
+	}
+	t.OnTxCommit = func(info trace.TableTxCommitStartInfo) func(trace.TableTxCommitDoneInfo) {
+		withTx(info.Context, info.Tx, false)
+
+		return nil
+	}
+	t.OnTxRollback = func(info trace.TableTxRollbackStartInfo) func(trace.TableTxRollbackDoneInfo) {
+		withTx(info.Context, info.Tx, false)
+
+		return nil
+	}
+
+	return t
+}
+
+// txTableFinish must be registered after spans.WithTraces: it ends transaction span
+// after commit or rollback span
+func txTableFinish(_ *adapter) (t trace.Table) {
+	t.OnTxCommit = func(info trace.TableTxCommitStartInfo) func(trace.TableTxCommitDoneInfo) {
+		tx := txs.get(info.Tx)
+		if tx == nil {
+			return nil
+		}
+
+		return func(info trace.TableTxCommitDoneInfo) {
+			tx.finish(txOutcomeCommit, info.Error)
+		}
+	}
+	t.OnTxRollback = func(info trace.TableTxRollbackStartInfo) func(trace.TableTxRollbackDoneInfo) {
+		tx := txs.get(info.Tx)
+		if tx == nil {
+			return nil
+		}
+
+		return func(info trace.TableTxRollbackDoneInfo) {
+			tx.finish(txOutcomeRollback, info.Error)
+		}
+	}
+	t.OnPoolPut = func(info trace.TablePoolPutStartInfo) func(trace.TablePoolPutDoneInfo) {
+		txs.release(info.Session)
+
+		return nil
+	}
+	t.OnSessionDelete = func(info trace.TableSessionDeleteStartInfo) func(trace.TableSessionDeleteDoneInfo) {
+		txs.release(info.Session)
+
+		return nil
+	}
+
+	return t
+}
+
+// txQuery must be registered before spans.WithTraces. Query service has no commit and
+// rollback events, so its transactions are ended with retry loop of query.Client.DoTx
+// or with release of session
+func txQuery(cfg *adapter) (t trace.Query) {
+	t.OnSessionBegin = func(info trace.QuerySessionBeginStartInfo) func(trace.QuerySessionBeginDoneInfo) {
+		tx := cfg.beginTx(info.Context, info.Session)
+
+		return func(info trace.QuerySessionBeginDoneInfo) {
+			var id string
+			if info.Tx != nil {
+				id = info.Tx.ID()
+			}
+			tx.begun(id, info.Error)
+		}
+	}
+	t.OnTxExec = func(info trace.QueryTxExecStartInfo) func(trace.QueryTxExecDoneInfo) {
+		tx := withTx(info.Context, info.Tx, true)
+		if tx == nil {
+			return nil
+		}
+
+		return func(info trace.QueryTxExecDoneInfo) {
+			tx.fail(info.Error)
+		}
+	}
+	t.OnTxQuery = func(info trace.QueryTxQueryStartInfo) func(trace.QueryTxQueryDoneInfo) {
+		tx := withTx(info.Context, info.Tx, true)
+		if tx == nil {
+			return nil
+		}
+
+		return func(info trace.QueryTxQueryDoneInfo) {
+			tx.fail(info.Error)
+		}
+	}
+	t.OnTxQueryResultSet = func(info trace.QueryTxQueryResultSetStartInfo) func(trace.QueryTxQueryResultSetDoneInfo) {
+		tx := withTx(info.Context, info.Tx, true)
+		if tx == nil {
+			return nil
+		}
+
+		return func(info trace.QueryTxQueryResultSetDoneInfo) {
+			tx.fail(info.Error)
+		}
+	}
+	t.OnTxQueryRow = func(info trace.QueryTxQueryRowStartInfo) func(trace.QueryTxQueryRowDoneInfo) {
+		tx := withTx(info.Context, info.Tx, true)
+		if tx == nil {
+			return nil
+		}
+
+		return func(info trace.QueryTxQueryRowDoneInfo) {
+			tx.fail(info.Error)
+		}
+	}
+	t.OnPoolPut = func(info trace.QueryPoolPutStartInfo) func(trace.QueryPoolPutDoneInfo) {
+		txs.release(info.Session)
+
+		return nil
+	}
+	t.OnSessionDelete = func(info trace.QuerySessionDeleteStartInfo) func(trace.QuerySessionDeleteDoneInfo) {
+		txs.release(info.Session)
+
+		return nil
+	}
+
+	return t
+}